@@ -0,0 +1,149 @@
+/*
+Copyright © 2026 minotto
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/charmbracelet/huh"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/llm"
+	"github.com/minotto165/progoat/internal/tutor"
+	"github.com/minotto165/progoat/internal/ui"
+	"github.com/minotto165/progoat/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+// askCmd represents the ask command
+var askCmd = &cobra.Command{
+	Use:   "ask [CourseID] [LessonID]",
+	Short: "Chat with the AI tutor about a lesson",
+	Long: `Ask the AI tutor questions about a lesson. The tutor sees the slides, the task,
+your current code and the output of your last run. The conversation is saved
+in the lesson directory and resumed the next time.`,
+	Args:         cobra.MaximumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		courseID, err := chooseCourse(args)
+		if err != nil {
+			return err
+		}
+
+		c, err := course.GetCourseStruct(courseID, coursesPath)
+		if err != nil {
+			return err
+		}
+
+		lesson, err := chooseLesson(c, args)
+		if err != nil {
+			return err
+		}
+		coursePath := filepath.Join(coursesPath, filepath.Base(c.ID))
+
+		chat, err := tutor.Open(course.LessonDir(coursePath, lesson))
+		if err != nil {
+			return err
+		}
+		if fresh, _ := cmd.Flags().GetBool("new"); fresh {
+			if err := chat.Clear(); err != nil {
+				return err
+			}
+		}
+
+		recorder := usage.NewRecorder(usagePath, "ask")
+		defer saveUsage(recorder)
+		ctx := usage.WithLesson(usage.WithRecorder(cmd.Context(), recorder), c.ID, lesson.ID)
+
+		fmt.Println(c.Title, "-", lesson.Title)
+		if len(chat.Messages) > 0 {
+			fmt.Printf("Resuming the conversation (%d messages). Use --new to start over.\n", len(chat.Messages))
+			for _, m := range chat.Messages {
+				if err := printChatMessage(m); err != nil {
+					return err
+				}
+			}
+		}
+
+		message, _ := cmd.Flags().GetString("message")
+		for {
+			question := message
+			if question == "" {
+				err := huh.NewText().
+					Title("You").
+					Description("Leave empty to quit.").
+					Value(&question).WithTheme(huh.ThemeBase()).Run()
+				if errors.Is(err, huh.ErrUserAborted) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+			question = strings.TrimSpace(question)
+			if question == "" {
+				return nil
+			}
+
+			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+			s.Suffix = " Thinking..."
+			s.Start()
+			answer, err := tutor.Answer(ctx, tutor.LessonContext(c, lesson, coursePath), chat.Messages, question)
+			s.Stop()
+			if err != nil {
+				return err
+			}
+			if err := chat.Add(question, answer); err != nil {
+				return err
+			}
+
+			if message == "" {
+				if err := printChatMessage(llm.ChatMessage{Role: llm.ChatUser, Content: question}); err != nil {
+					return err
+				}
+			}
+			if err := printChatMessage(chat.Messages[len(chat.Messages)-1]); err != nil {
+				return err
+			}
+
+			// -m は1回だけ質問する
+			if message != "" {
+				return nil
+			}
+		}
+	},
+}
+
+func printChatMessage(m llm.ChatMessage) error {
+	md := "### 🐐 Tutor\n" + m.Content
+	if m.Role == llm.ChatUser {
+		md = "### 🙋 You\n" + m.Content
+	}
+	out, err := ui.RenderWithTerminalWidth(md)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// askCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	askCmd.Flags().StringP("message", "m", "", "Ask one question and exit")
+	askCmd.Flags().Bool("new", false, "Start a new conversation instead of resuming the saved one")
+}
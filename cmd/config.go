@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/minotto165/progoat/internal/llm"
@@ -24,16 +25,23 @@ Settings are saved locally on your machine.`,
 		var apiKey string
 
 		// プロバイダ選択
+		providerOptions := []huh.Option[string]{}
+		for _, b := range llm.Backends() {
+			if b.Hidden {
+				continue
+			}
+			label := b.Name
+			if b.FetchModels != nil {
+				label += " (auto-fetches latest models)"
+			}
+			providerOptions = append(providerOptions, huh.NewOption(label, b.ID))
+		}
+
 		err := huh.NewForm(
 			huh.NewGroup(
 				huh.NewSelect[string]().
 					Title("Pick a provider").
-					Options(
-						huh.NewOption("Gemini (auto-fetches latest models)", "gemini"),
-						huh.NewOption("OpenAI", "openai"),
-						huh.NewOption("Anthropic", "anthropic"),
-						huh.NewOption("Z.AI", "zai"),
-					).
+					Options(providerOptions...).
 					Value(&provider),
 			),
 		).WithTheme(huh.ThemeBase()).Run()
@@ -42,80 +50,57 @@ Settings are saved locally on your machine.`,
 			return fmt.Errorf("Cancelled: %w", err)
 		}
 
-		var keyTitle string
-		switch provider {
-		case "gemini":
-			keyTitle = "Gemini API Key"
-		case "openai":
-			keyTitle = "OpenAI API Key"
-		case "anthropic":
-			keyTitle = "Anthropic API Key"
-		case "zai":
-			keyTitle = "Z.AI API Key"
+		backend, err := llm.LookupBackend(provider)
+		if err != nil {
+			return err
 		}
 
 		// 設定読み込み
-		apiKey = viper.GetString(fmt.Sprintf("providers.%s.api_key", provider))
+		settings := llm.LoadSettings(provider)
+		apiKey = settings.APIKey
+		baseURL := settings.BaseURL
+		if baseURL == "" {
+			baseURL = backend.DefaultBaseURL
+		}
 
 		// APIキー入力
+		var fields []huh.Field
+		if backend.UsesBaseURL {
+			fields = append(fields, huh.NewInput().
+				Title(backend.Name+" Base URL").
+				Placeholder("http://localhost:8080/v1").
+				Validate(llm.ValidateBaseURL).
+				Value(&baseURL))
+		}
+
+		keyInput := huh.NewInput().
+			Title(backend.Name + " API Key").
+			EchoMode(huh.EchoModePassword).
+			Value(&apiKey)
+		if backend.ValidateKey != nil {
+			keyInput = keyInput.Validate(backend.ValidateKey)
+		} else {
+			keyInput = keyInput.Title(backend.Name + " API Key (optional)")
+		}
+		fields = append(fields, keyInput)
+
 		err = huh.NewForm(
-			huh.NewGroup(
-				huh.NewInput().
-					Title(keyTitle).
-					EchoMode(huh.EchoModePassword).
-					Value(&apiKey),
-			),
+			huh.NewGroup(fields...),
 		).WithTheme(huh.ThemeBase()).Run()
 		if err != nil {
 			return fmt.Errorf("Cancelled: %w", err)
 		}
 
-		// フォールバック
-		var geminiDefaultOptions = []huh.Option[string]{
-			huh.NewOption("Gemini 3.1 Pro Preview", "gemini-3.1-pro-preview"),
-			huh.NewOption("Gemini 3 Flash Preview", "gemini-3-flash-preview"),
-			huh.NewOption("Gemini Flash Latest", "gemini-flash-latest"),
-			huh.NewOption("Gemini Flash Lite Latest", "gemini-flash-lite-latest"),
-			huh.NewOption("Gemini 2.5 Pro", "gemini-2.5-pro"),
+		if apiKey != "" {
+			settings.APIKey = apiKey
 		}
+		settings.BaseURL = baseURL
 
-		var options []huh.Option[string]
-		switch provider {
-		case "gemini":
-			keyForFetch := apiKey
-			if keyForFetch == "" {
-				keyForFetch = viper.GetString("providers.gemini.api_key")
-			}
-			if keyForFetch != "" {
-				if fetched, err := llm.FetchGeminiModels(cmd.Context(), keyForFetch); err == nil {
-					options = fetched
-					fmt.Println("Fetched latest Gemini models")
-				} else {
-					fmt.Printf("Could not fetch Gemini models (%s), using defaults\n", err)
-					options = geminiDefaultOptions
-				}
-			} else {
-				options = geminiDefaultOptions
-			}
-		case "openai":
-			options = []huh.Option[string]{
-				huh.NewOption("GPT-5.4", "gpt-5.4"),
-				huh.NewOption("GPT-5 mini", "gpt-5-mini"),
-				huh.NewOption("GPT-5 nano", "gpt-5-nano"),
-			}
-		case "anthropic":
-			options = []huh.Option[string]{
-				huh.NewOption("Claude Opus 4.6", "claude-opus-4-6"),
-				huh.NewOption("Claude Sonnet 4.6", "claude-sonnet-4-6"),
-				huh.NewOption("Claude Haiku 4.5", "claude-haiku-4-5-20251001"),
-			}
-		case "zai":
-			options = []huh.Option[string]{
-				huh.NewOption("GLM-5", "glm-5"),
-				huh.NewOption("GLM-4.7", "glm-4.7"),
-				huh.NewOption("GLM-4.7-FlashX", "glm-4.7-flashx"),
-				huh.NewOption("GLM-4.7-Flash", "glm-4.7-flash"),
-			}
+		options, fetched, err := backend.Models(cmd.Context(), settings)
+		if err != nil {
+			fmt.Printf("Could not fetch %s models (%s), using defaults\n", backend.Name, err)
+		} else if fetched {
+			fmt.Printf("Fetched latest %s models\n", backend.Name)
 		}
 
 		// 設定読み込み
@@ -127,15 +112,9 @@ Settings are saved locally on your machine.`,
 		// 詳細設定
 		err = huh.NewForm(
 			huh.NewGroup(
-				huh.NewSelect[string]().
-					Title("Model for course generation").
-					Options(options...).
-					Value(&genModel),
+				modelField("Model for course generation", options, &genModel),
 
-				huh.NewSelect[string]().
-					Title("Model for judging").
-					Options(options...).
-					Value(&judgeModel),
+				modelField("Model for judging", options, &judgeModel),
 
 				huh.NewConfirm().
 					Title("Save settings?").
@@ -155,6 +134,9 @@ Settings are saved locally on your machine.`,
 			if apiKey != "" {
 				viper.Set(fmt.Sprintf("providers.%s.api_key", provider), apiKey)
 			}
+			if backend.UsesBaseURL {
+				viper.Set(fmt.Sprintf("providers.%s.base_url", provider), baseURL)
+			}
 			viper.Set(fmt.Sprintf("providers.%s.gen_model", provider), genModel)
 			viper.Set(fmt.Sprintf("providers.%s.judge_model", provider), judgeModel)
 
@@ -171,6 +153,27 @@ Settings are saved locally on your machine.`,
 	},
 }
 
+// modelField shows a select for the known models, or a text input when the
+// provider has no model list (e.g. self-hosted servers).
+func modelField(title string, options []huh.Option[string], value *string) huh.Field {
+	if len(options) > 0 {
+		return huh.NewSelect[string]().
+			Title(title).
+			Options(options...).
+			Value(value)
+	}
+	return huh.NewInput().
+		Title(title).
+		Placeholder("model name").
+		Validate(func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("model name is required")
+			}
+			return nil
+		}).
+		Value(value)
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 
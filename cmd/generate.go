@@ -5,11 +5,14 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/charmbracelet/huh"
+	"github.com/minotto165/progoat/internal/course"
 	"github.com/minotto165/progoat/internal/llm"
+	"github.com/minotto165/progoat/internal/usage"
 	"github.com/spf13/cobra"
 )
 
@@ -61,19 +64,60 @@ AI will create lessons, including slides and coding exercises.`,
 
 		fmt.Println("Input >", prompt)
 
-		courseTitle := ""
+		// Ctrl-C stops the generation; lessons written so far are kept
+		recorder := usage.NewRecorder(usagePath, "generate")
+		defer saveUsage(recorder)
+		ctx := usage.WithRecorder(cmd.Context(), recorder)
 
+		// 1. Outline
 		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-		s.Suffix = " Generating..."
+		s.Suffix = " Planning..."
 		s.Start()
-
-		courseTitle, err := llm.GenerateCourse(prompt, length, coursesPath)
+		defer s.Stop()
+
+		planned := 0
+		outline, err := llm.GenerateOutline(ctx, prompt, length, func(l llm.OutlineLesson) {
+			planned++
+			s.Lock()
+			fmt.Printf("\r\033[K  %d. %s\n", planned, l.Title)
+			s.Unlock()
+		})
+		s.Stop()
 		if err != nil {
 			return err
 		}
 
+		recorder.CourseID = outline.ID
+
+		skipReview, _ := cmd.Flags().GetBool("yes")
+		if !skipReview {
+			if err := reviewOutline(&outline); err != nil {
+				return err
+			}
+		}
+
+		// 2. Lessons
+		s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+		s.Suffix = fmt.Sprintf(" Generating lessons... (0/%d)", len(outline.Lessons))
+		s.Start()
+
+		done := 0
+		courseTitle, err := llm.ExpandOutline(ctx, prompt, outline, coursesPath, func(l course.Lesson) {
+			done++
+			s.Lock()
+			fmt.Printf("\r\033[K  ✓ %s\n", l.Title)
+			s.Suffix = fmt.Sprintf(" Generating lessons... (%d/%d)", done, len(outline.Lessons))
+			s.Unlock()
+		})
 		s.Stop()
 
+		if err != nil {
+			if courseTitle != "" {
+				fmt.Println("Partial course saved:", courseTitle)
+			}
+			return err
+		}
+
 		if courseTitle != "" {
 			fmt.Println("Course generated:", courseTitle)
 		}
@@ -81,6 +125,99 @@ AI will create lessons, including slides and coding exercises.`,
 	},
 }
 
+// reviewOutline lets the user edit the course title, description and lesson
+// plan before the lessons are generated. Lessons are edited as
+// "lesson_id | title | objectives" lines.
+func reviewOutline(outline *llm.Outline) error {
+	lines := []string{}
+	for _, l := range outline.Lessons {
+		// "|" は区切り文字なので、AI が付けたタイトルなどからは取り除く
+		fields := []string{l.ID, l.Title, l.Objectives}
+		for i, f := range fields {
+			fields[i] = strings.ReplaceAll(f, "|", "/")
+		}
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+	lessonsText := strings.Join(lines, "\n")
+
+	var confirm bool
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Course title").
+				Value(&outline.Title),
+			huh.NewText().
+				Title("Description").
+				Value(&outline.Description),
+			huh.NewText().
+				Title("Lessons").
+				Description("One lesson per line: lesson_id | title | objectives").
+				Lines(min(len(lines)+2, 15)).
+				Validate(func(s string) error {
+					lessons, err := parseOutlineLessons(s)
+					if err != nil {
+						return err
+					}
+					edited := *outline
+					edited.Lessons = lessons
+					return edited.Validate()
+				}).
+				Value(&lessonsText),
+			huh.NewConfirm().
+				Title("Generate lessons?").
+				Affirmative("Generate").
+				Negative("Cancel").
+				Value(&confirm),
+		),
+	).WithTheme(huh.ThemeBase()).Run()
+	if err != nil {
+		return fmt.Errorf("Cancelled: %w", err)
+	}
+	if !confirm {
+		return fmt.Errorf("Cancelled.")
+	}
+
+	outline.Lessons, err = parseOutlineLessons(lessonsText)
+	return err
+}
+
+// parseOutlineLessons parses the edited lesson plan. A line must have exactly
+// three fields: "|" cannot appear in a title or objectives, since there is no
+// telling which field an extra one belongs to.
+func parseOutlineLessons(text string) ([]llm.OutlineLesson, error) {
+	var lessons []llm.OutlineLesson
+	seen := map[string]bool{}
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.Split(line, "|")
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("line %d: expected 'lesson_id | title | objectives'", i+1)
+		}
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("line %d: '|' separates the fields and cannot be used in a title or objectives", i+1)
+		}
+		l := llm.OutlineLesson{
+			ID:         strings.TrimSpace(parts[0]),
+			Title:      strings.TrimSpace(parts[1]),
+			Objectives: strings.TrimSpace(parts[2]),
+		}
+		if l.ID == "" || l.Title == "" {
+			return nil, fmt.Errorf("line %d: lesson_id and title are required", i+1)
+		}
+		if seen[l.ID] {
+			return nil, fmt.Errorf("line %d: duplicate lesson_id '%s'", i+1, l.ID)
+		}
+		seen[l.ID] = true
+		lessons = append(lessons, l)
+	}
+	if len(lessons) == 0 {
+		return nil, fmt.Errorf("at least one lesson is required")
+	}
+	return lessons, nil
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
@@ -93,4 +230,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	generateCmd.Flags().StringP("length", "l", "medium", "Course length (short, medium, long)")
+	generateCmd.Flags().BoolP("yes", "y", false, "Generate the lessons without reviewing the outline")
 }
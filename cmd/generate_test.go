@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestParseOutlineLessons(t *testing.T) {
+	lessons, err := parseOutlineLessons("intro | Hello, World | Print a line\n\nvars|Variables|Declare and assign\n")
+	if err != nil {
+		t.Fatalf("parseOutlineLessons: %v", err)
+	}
+	if len(lessons) != 2 || lessons[0].Title != "Hello, World" || lessons[1].Objectives != "Declare and assign" {
+		t.Errorf("lessons = %+v", lessons)
+	}
+
+	for _, text := range []string{
+		"",
+		"intro | Hello",
+		"intro | Pipes | and more | Use a|b",
+		" | Hello | Print a line",
+		"intro | Hello | a\nintro | Again | b",
+	} {
+		if _, err := parseOutlineLessons(text); err == nil {
+			t.Errorf("parseOutlineLessons(%q) = nil error", text)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2026 minotto
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/history"
+	"github.com/minotto165/progoat/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [CourseID] [LessonID]",
+	Short: "Browse your past submissions",
+	Long: `Every judged submission is kept in the course directory with a snapshot of
+its code, the run output and the verdict. Without a lesson, show how many
+attempts each lesson took. With a lesson, list its attempts; use --attempt to
+see one of them with the changes since the previous one, or --diff to walk
+through every change.`,
+	Args:         cobra.MaximumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		courseID, err := chooseCourse(args)
+		if err != nil {
+			return err
+		}
+
+		c, err := course.GetCourseStruct(courseID, coursesPath)
+		if err != nil {
+			return err
+		}
+		coursePath := filepath.Join(coursesPath, filepath.Base(c.ID))
+
+		if len(args) < 2 {
+			return printCourseHistory(c, coursePath)
+		}
+
+		lesson, err := findLesson(c, args[1])
+		if err != nil {
+			return err
+		}
+
+		attempts, err := history.Load(coursePath, lesson)
+		if err != nil {
+			return err
+		}
+		if len(attempts) == 0 {
+			fmt.Println("No attempts recorded for this lesson yet.")
+			return nil
+		}
+
+		n, _ := cmd.Flags().GetInt("attempt")
+		if showDiffs, _ := cmd.Flags().GetBool("diff"); showDiffs {
+			return printDiffs(c, lesson, coursePath, attempts)
+		}
+		if n != 0 {
+			if n < 1 || n > len(attempts) {
+				return fmt.Errorf("attempt %d not found: the lesson has %d attempts", n, len(attempts))
+			}
+			full, _ := cmd.Flags().GetBool("code")
+			return printAttempt(c, lesson, coursePath, attempts, n, full)
+		}
+		return printAttempts(c, lesson, coursePath, attempts)
+	},
+}
+
+func resultMark(isCorrect bool) string {
+	if isCorrect {
+		return "✅ Correct"
+	}
+	return "❌ Incorrect"
+}
+
+// printCourseHistory shows the number of attempts of each lesson.
+func printCourseHistory(c course.Course, coursePath string) error {
+	idStyle := lipgloss.NewStyle().Width(20)
+	titleStyle := lipgloss.NewStyle().Width(35)
+	attemptsStyle := lipgloss.NewStyle().Width(10)
+	resultStyle := lipgloss.NewStyle().Width(15)
+
+	fmt.Println(c.Title)
+	fmt.Print("\n")
+	fmt.Printf("%s %s %s %s %s\n",
+		idStyle.Render("ID"),
+		titleStyle.Render("TITLE"),
+		attemptsStyle.Render("ATTEMPTS"),
+		resultStyle.Render("RESULT"),
+		"LAST")
+
+	for _, l := range c.Lessons {
+		attempts, err := history.Load(coursePath, l)
+		if err != nil {
+			return err
+		}
+		result, last := "-", "-"
+		if len(attempts) > 0 {
+			// 一度でも正解していれば正解とする
+			solved := slices.ContainsFunc(attempts, func(a history.Attempt) bool { return a.IsCorrect })
+			result = resultMark(solved)
+			last = attempts[len(attempts)-1].Time.Local().Format(time.DateTime)
+		}
+		fmt.Printf("%s %s %s %s %s\n",
+			idStyle.Render(l.ID),
+			titleStyle.Render(l.Title),
+			attemptsStyle.Render(fmt.Sprint(len(attempts))),
+			resultStyle.Render(result),
+			last)
+	}
+
+	fmt.Print("\n")
+	fmt.Printf("Run 'progoat history %s [LessonID]' to see the attempts of a lesson.\n", c.ID)
+	return nil
+}
+
+// printAttempts lists the attempts of a lesson with the lines changed since
+// the previous one.
+func printAttempts(c course.Course, lesson course.Lesson, coursePath string, attempts []history.Attempt) error {
+	numberStyle := lipgloss.NewStyle().Width(4)
+	timeStyle := lipgloss.NewStyle().Width(21)
+	resultStyle := lipgloss.NewStyle().Width(15)
+	scoreStyle := lipgloss.NewStyle().Width(7)
+	codeStyle := lipgloss.NewStyle().Width(10)
+
+	fmt.Println(c.Title, "-", lesson.Title)
+	fmt.Print("\n")
+	fmt.Printf("%s %s %s %s %s %s\n",
+		numberStyle.Render("#"),
+		timeStyle.Render("TIME"),
+		resultStyle.Render("RESULT"),
+		scoreStyle.Render("SCORE"),
+		codeStyle.Render("CODE"),
+		"CHANGES")
+
+	var previous string
+	for i, a := range attempts {
+		code, err := history.Code(coursePath, lesson, a)
+		if err != nil {
+			return err
+		}
+		changes := "-"
+		if i > 0 {
+			changes = diffStat(previous, code)
+		}
+		previous = code
+
+		score := "-"
+		if a.Score != nil {
+			score = fmt.Sprint(a.Score.Overall)
+		}
+		fmt.Printf("%s %s %s %s %s %s\n",
+			numberStyle.Render(fmt.Sprint(i+1)),
+			timeStyle.Render(a.Time.Local().Format(time.DateTime)),
+			resultStyle.Render(resultMark(a.IsCorrect)),
+			scoreStyle.Render(score),
+			codeStyle.Render(shortHash(a.CodeHash)),
+			changes)
+	}
+
+	fmt.Print("\n")
+	fmt.Printf("Run 'progoat history %s %s --attempt N' to see an attempt.\n", c.ID, lesson.ID)
+	return nil
+}
+
+// diffStat summarizes the changes between two submissions as "+added -removed".
+func diffStat(from, to string) string {
+	added, removed := history.Stat(from, to)
+	if added == 0 && removed == 0 {
+		return "unchanged"
+	}
+	return fmt.Sprintf("+%d -%d", added, removed)
+}
+
+// shortHash abbreviates a code hash for display. Hand-edited or truncated
+// history entries may hold a shorter one.
+func shortHash(hash string) string {
+	if len(hash) < 8 {
+		if hash == "" {
+			return "-"
+		}
+		return hash
+	}
+	return hash[:8]
+}
+
+// printAttempt shows attempt n (1-based) with its verdict, its output and
+// the changes since the previous attempt, or the whole code when full is set
+// or it is the first one.
+func printAttempt(c course.Course, lesson course.Lesson, coursePath string, attempts []history.Attempt, n int, full bool) error {
+	a := attempts[n-1]
+	code, err := history.Code(coursePath, lesson, a)
+	if err != nil {
+		return err
+	}
+
+	var md strings.Builder
+	fmt.Fprintf(&md, "# Attempt %d of %d: %s\n", n, len(attempts), lesson.Title)
+	fmt.Fprintf(&md, "%s · %s · `%s`\n\n", resultMark(a.IsCorrect), a.Time.Local().Format(time.DateTime), shortHash(a.CodeHash))
+	if s := a.Score; s != nil {
+		fmt.Fprintf(&md, "## Score: %d / 100\n| Correctness | Style | Idiomatic use | Edge cases |\n|---|---|---|---|\n| %d | %d | %d | %d |\n\n",
+			s.Overall, s.Correctness, s.Style, s.Idiomatic, s.EdgeCases)
+	}
+	if a.Advice != "" {
+		fmt.Fprintf(&md, "## Advice\n%s\n\n", a.Advice)
+	}
+	fmt.Fprintf(&md, "## Output\n```\n%s\n```\n\n", strings.TrimRight(a.Output, "\n"))
+
+	if n == 1 || full {
+		fmt.Fprintf(&md, "## Code\n```%s\n%s\n```\n", c.ProgrammingLanguage, strings.TrimRight(code, "\n"))
+	} else {
+		previous, err := history.Code(coursePath, lesson, attempts[n-2])
+		if err != nil {
+			return err
+		}
+		md.WriteString(diffMarkdown(previous, code, n-1, n))
+	}
+
+	out, err := ui.RenderWithTerminalWidth(md.String())
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// printDiffs walks through the changes between every pair of consecutive
+// attempts.
+func printDiffs(c course.Course, lesson course.Lesson, coursePath string, attempts []history.Attempt) error {
+	var md strings.Builder
+	fmt.Fprintf(&md, "# %s - %s\n", c.Title, lesson.Title)
+
+	var previous string
+	for i, a := range attempts {
+		code, err := history.Code(coursePath, lesson, a)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintf(&md, "\n%s · %s\n", resultMark(a.IsCorrect), a.Time.Local().Format(time.DateTime))
+			md.WriteString(diffMarkdown(previous, code, i, i+1))
+		}
+		previous = code
+	}
+	if len(attempts) < 2 {
+		md.WriteString("\nOnly one attempt so far.\n")
+	}
+
+	out, err := ui.RenderWithTerminalWidth(md.String())
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func diffMarkdown(from, to string, fromN, toN int) string {
+	title := fmt.Sprintf("## Changes from attempt %d to %d\n", fromN, toN)
+	diff := history.Diff(from, to, fmt.Sprintf("attempt %d", fromN), fmt.Sprintf("attempt %d", toN))
+	if diff == "" {
+		return title + "No changes in the code.\n"
+	}
+	return title + "```diff\n" + diff + "```\n"
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// historyCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	historyCmd.Flags().IntP("attempt", "a", 0, "Show an attempt (1 is the first) and what changed since the previous one")
+	historyCmd.Flags().Bool("code", false, "With --attempt, show the whole code instead of the changes")
+	historyCmd.Flags().Bool("diff", false, "Show the changes between every pair of consecutive attempts")
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2026 minotto
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/huh"
+	"github.com/minotto165/progoat/internal/course"
+)
+
+// chooseCourse returns the course ID given as the first argument, or asks
+// the user to pick one of the installed courses.
+func chooseCourse(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	courses, err := course.GetCourses(coursesPath)
+	if err != nil {
+		return "", err
+	}
+
+	options := []huh.Option[string]{}
+	for _, c := range courses {
+		title := c.Title
+		id := c.ID
+		key := fmt.Sprint(title, "(id: ", id, ")")
+		options = append(options, huh.NewOption(key, id))
+	}
+
+	var courseID string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Choose Course").
+				Options(options...).
+				Value(&courseID),
+		),
+	).WithTheme(huh.ThemeBase())
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return courseID, nil
+}
+
+// findLesson returns the lesson of the course with the given ID.
+func findLesson(c course.Course, lessonID string) (course.Lesson, error) {
+	i := slices.IndexFunc(c.Lessons, func(l course.Lesson) bool { return l.ID == lessonID })
+	if i == -1 {
+		return course.Lesson{}, fmt.Errorf("lesson '%s' not found in course '%s'", lessonID, c.ID)
+	}
+	return c.Lessons[i], nil
+}
+
+// chooseLesson returns the lesson given as the second argument, or asks the
+// user to pick one, starting from the lesson they are on.
+func chooseLesson(c course.Course, args []string) (course.Lesson, error) {
+	if len(args) > 1 {
+		return findLesson(c, args[1])
+	}
+
+	// 続きのレッスンを初期値にする
+	progress, err := course.LoadProgress(c.ID, progressPath)
+	if err != nil {
+		return course.Lesson{}, err
+	}
+	lessonID := progress.CurrentLesson
+
+	options := []huh.Option[string]{}
+	for _, l := range c.Lessons {
+		options = append(options, huh.NewOption(l.Title, l.ID))
+	}
+
+	err = huh.NewSelect[string]().
+		Title("Choose Lesson").
+		Options(options...).
+		Value(&lessonID).WithTheme(huh.ThemeBase()).Run()
+	if err != nil {
+		return course.Lesson{}, err
+	}
+	return findLesson(c, lessonID)
+}
@@ -8,8 +8,6 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/charmbracelet/huh"
-	"github.com/minotto165/progoat/internal/course"
 	"github.com/spf13/cobra"
 )
 
@@ -22,37 +20,9 @@ You can select a course from the list or provide the CourseID as an argument.`,
 	Args:         cobra.MaximumNArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var courseID string
-
-		if len(args) > 0 {
-			courseID = args[0]
-		} else {
-
-			courses, err := course.GetCourses(coursesPath)
-			if err != nil {
-				return err
-			}
-
-			options := []huh.Option[string]{}
-			for _, c := range courses {
-				title := c.Title
-				id := c.ID
-				key := fmt.Sprint(title, "(id: ", id, ")")
-				options = append(options, huh.NewOption(key, id))
-			}
-
-			form := huh.NewForm(
-				huh.NewGroup(
-					huh.NewSelect[string]().
-						Title("Choose Course").
-						Options(options...).
-						Value(&courseID),
-				),
-			).WithTheme(huh.ThemeBase())
-			err = form.Run()
-			if err != nil {
-				return err
-			}
+		courseID, err := chooseCourse(args)
+		if err != nil {
+			return err
 		}
 
 		// 安全装置
@@ -62,7 +32,7 @@ You can select a course from the list or provide the CourseID as an argument.`,
 		}
 		path := filepath.Join(coursesPath, baseID)
 
-		err := os.RemoveAll(path)
+		err = os.RemoveAll(path)
 		if err != nil {
 			return err
 		}
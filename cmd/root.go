@@ -4,7 +4,9 @@ Copyright © 2026 minotto
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -21,8 +23,17 @@ It provides an interactive learning experience with explanations and exercises.`
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Ctrl-C cancels the context of the running command; a second Ctrl-C exits
+// immediately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
@@ -33,6 +44,7 @@ var basePath = filepath.Join(homePath, ".progoat")
 var coursesPath = filepath.Join(basePath, "courses")
 var configPath = filepath.Join(basePath, "config.yaml")
 var progressPath = filepath.Join(basePath, "progress.json")
+var usagePath = filepath.Join(basePath, "usage.jsonl")
 
 func init() {
 	os.MkdirAll(basePath, 0700)
@@ -40,6 +52,11 @@ func init() {
 	viper.SetConfigFile(configPath)
 	viper.ReadInConfig()
 
+	// Environment overrides, mainly for tests and recording demos
+	viper.BindEnv("active_provider", "PROGOAT_PROVIDER")
+	viper.BindEnv("providers.fake.fixtures", "PROGOAT_FIXTURES")
+	viper.BindEnv("record_fixtures", "PROGOAT_RECORD")
+
 	os.MkdirAll(coursesPath, 0755)
 
 }
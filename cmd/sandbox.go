@@ -0,0 +1,24 @@
+/*
+Copyright © 2026 minotto
+*/
+package cmd
+
+import (
+	"github.com/minotto165/progoat/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// sandboxCmd is started by the sandbox runner inside the new namespaces. It
+// is not meant to be run by hand.
+var sandboxCmd = &cobra.Command{
+	Use:                runner.SandboxCommand + " <spec> <command> [args...]",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		runner.SandboxMain(args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sandboxCmd)
+}
@@ -4,28 +4,18 @@ Copyright © 2026 minotto
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
-	"time"
+	"slices"
 
-	"github.com/briandowns/spinner"
 	"github.com/charmbracelet/huh"
 	"github.com/minotto165/progoat/internal/course"
-	"github.com/minotto165/progoat/internal/llm"
-	"github.com/minotto165/progoat/internal/ui"
-	"github.com/pkg/browser"
+	"github.com/minotto165/progoat/internal/tui"
+	"github.com/minotto165/progoat/internal/usage"
 	"github.com/spf13/cobra"
 )
 
-type JudgeResult struct {
-	IsCorrect bool   `json:"is_correct"`
-	Advice    string `json:"advice"`
-}
-
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start [CourseID]",
@@ -36,40 +26,18 @@ Read the slides, write your code, and get feedback from the AI judge.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		var courseID string
-
-		if len(args) > 0 {
-			courseID = args[0]
-		} else {
-
-			courses, err := course.GetCourses(coursesPath)
-			if err != nil {
-				return err
-			}
+		courseID, err := chooseCourse(args)
+		if err != nil {
+			return err
+		}
 
-			options := []huh.Option[string]{}
-			for _, c := range courses {
-				title := c.Title
-				id := c.ID
-				key := fmt.Sprint(title, "(id: ", id, ")")
-				options = append(options, huh.NewOption(key, id))
-			}
+		recorder := usage.NewRecorder(usagePath, "start")
+		defer saveUsage(recorder)
 
-			form := huh.NewForm(
-				huh.NewGroup(
-					huh.NewSelect[string]().
-						Title("Choose Course").
-						Options(options...).
-						Value(&courseID),
-				),
-			).WithTheme(huh.ThemeBase())
-			err = form.Run()
-			if err != nil {
-				return err
-			}
-		}
+		watch, _ := cmd.Flags().GetBool("watch")
+		lessonID, _ := cmd.Flags().GetString("lesson")
 
-		err := startCourse(courseID)
+		err = startCourse(usage.WithRecorder(cmd.Context(), recorder), courseID, startOptions{watch: watch, lessonID: lessonID})
 		if err != nil {
 			return err
 		}
@@ -78,7 +46,13 @@ Read the slides, write your code, and get feedback from the AI judge.`,
 	},
 }
 
-func startCourse(courseID string) error {
+// startOptions are the flags of the start command.
+type startOptions struct {
+	watch    bool   // judge automatically when the lesson file is saved
+	lessonID string // open this lesson instead of asking where to continue
+}
+
+func startCourse(ctx context.Context, courseID string, opts startOptions) error {
 
 	progressStatus, currentLesson, err := course.LoadProgressStatus(courseID, progressPath)
 	if err != nil {
@@ -87,8 +61,11 @@ func startCourse(courseID string) error {
 
 	var action string
 
-	switch progressStatus {
-	case course.Completed:
+	switch {
+	case opts.lessonID != "":
+		action = "keep"
+
+	case progressStatus == course.Completed:
 		err = huh.NewSelect[string]().
 			Title("Course already completed.").
 			Options(
@@ -99,7 +76,7 @@ func startCourse(courseID string) error {
 			return err
 		}
 
-	case course.InProgress:
+	case progressStatus == course.InProgress:
 		err = huh.NewSelect[string]().
 			Title("Course in progress.").
 			Options(
@@ -115,13 +92,18 @@ func startCourse(courseID string) error {
 	}
 
 	if action == "reset" {
-		courseJson, err := course.GetCourseJson(courseID, coursesPath)
+		c, err := course.GetCourseStruct(courseID, coursesPath)
 		if err != nil {
 			return err
 		}
-		course.SaveCourse(courseJson, coursesPath)
+		// 検証ルールが変わる前に生成したコースもやり直せるよう、検証せずに書き戻す
+		if _, err := course.WriteCourse(c, coursesPath); err != nil {
+			return fmt.Errorf("failed to reset the lesson files: %w", err)
+		}
 
-		course.ResetProgress(courseID, progressPath)
+		if err := course.ResetProgress(courseID, progressPath); err != nil {
+			return err
+		}
 	}
 
 	c, err := course.GetCourseStruct(courseID, coursesPath)
@@ -130,218 +112,29 @@ func startCourse(courseID string) error {
 	}
 	coursePath := filepath.Join(coursesPath, filepath.Base(c.ID))
 
-	fmt.Println("[INFO] Course Directory:", coursePath)
-
-	for i, l := range c.Lessons {
-
-		if action == "continue" {
-			if currentLesson != l.ID {
-				continue
-			} else {
-				action = "keep"
-			}
-		}
-
-		ui.ClearScreen()
-		slides := l.Slides
-		for i, s := range slides {
-			out, err := ui.RenderWithTerminalWidth(s)
-			if err != nil {
-				return err
-			}
-			title := fmt.Sprint(c.Title, " - ", l.Title, ": Page ", i+1)
-			fmt.Println(title)
-			fmt.Print(out)
-
-			fmt.Print("[Enter] Next page")
-			fmt.Scanln()
-			fmt.Print("\033[1A\033[K")
-			fmt.Print("\n\n\n")
-
-		}
-
-		lessonPath := filepath.Clean(filepath.Join(coursePath, filepath.Base(l.ID)))
-		filePath := filepath.Clean(filepath.Join(lessonPath, filepath.Base(l.FileName)))
-		task := fmt.Sprintf("%s\n%s\n\n**File to edit:**\n```text\n%s\n```\n*DISCLAIMER: AI-generated code is executed locally. Use at your own risk.*",
-			"## Task:",
-			l.TaskDescription,
-			filePath,
-		)
-		out, err := ui.RenderWithTerminalWidth(task)
-		if err != nil {
-			return err
+	start := 0
+	if opts.lessonID != "" {
+		start = slices.IndexFunc(c.Lessons, func(l course.Lesson) bool { return l.ID == opts.lessonID })
+		if start == -1 {
+			return fmt.Errorf("lesson '%s' not found in course '%s'", opts.lessonID, c.ID)
 		}
-
-		title := fmt.Sprint(c.Title, " - ", l.Title, ": Task")
-		fmt.Println(title)
-
-		fmt.Print(out)
-		for {
-
-			fmt.Print("Edit and save the file, then hit Enter.")
-			fmt.Scanln()
-
-			fmt.Print("\033[1A\033[K")
-			fmt.Print("\n\n\n")
-
-			title = fmt.Sprint(c.Title, " - ", l.Title, ": Result")
-			fmt.Println(title)
-			response, err := judge(c, l, c.ProgrammingLanguage, filePath)
-
-			//for DEBUG...
-			// response, err = JudgeResult{
-			// 	IsCorrect: false,
-			// 	Advice:    "TEMP",
-			// }, nil
-
-			if err != nil {
-				return err
-			}
-
-			isCorrect := response.IsCorrect
-			advice := response.Advice
-
-			var result string
-			var enterMessage string
-
-			if isCorrect {
-				result += "## 🎉 CORRECT!  \n\n"
-				enterMessage = "[Enter] Next Lesson"
-			} else {
-				result += "## ❌ WRONG...  \n\n"
-				enterMessage = "[Enter] Retry"
-			}
-
-			result += "### AI Advice  \n"
-			result += "> " + advice
-
-			out, err = ui.RenderWithTerminalWidth(result)
-			if err != nil {
-				return err
-			}
-
-			fmt.Print(out)
-
-			fmt.Print(enterMessage)
-			fmt.Scanln()
-
-			if isCorrect {
-				var currentLessonID string
-				if i+1 >= len(c.Lessons) {
-					currentLessonID = ""
-				} else {
-					currentLessonID = c.Lessons[i+1].ID
-				}
-				course.SaveProgress(courseID, l.ID, currentLessonID, progressPath, len(c.Lessons))
+	}
+	if action == "continue" {
+		for i, l := range c.Lessons {
+			if l.ID == currentLesson {
+				start = i
 				break
 			}
-
-			fmt.Print("\n")
 		}
 	}
 
-	ui.ClearScreen()
-
-	message := fmt.Sprintf("## 🎉 Course Completed! 🐐 \n\nYou've completed the course: %s", c.Title)
-	out, err := ui.RenderWithTerminalWidth(message)
-	if err != nil {
-		return err
-	}
-
-	title := fmt.Sprint(c.Title, " - ", ": Finished!")
-	fmt.Println(title)
-
-	fmt.Print(out)
-
-	return nil
-}
-
-func judge(course course.Course, lesson course.Lesson, language, filePath string) (JudgeResult, error) {
-
-	var judgeResult JudgeResult
-
-	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	s.Suffix = " Running..."
-	s.Start()
-	defer s.Stop()
-
-	output, err := run(language, filePath)
-	s.Stop()
-	if err != nil {
-		return judgeResult, err
-	}
-
-	outputMd := "## Execution Output\n"
-	outputMd += "> " + output
-
-	out, err := ui.RenderWithTerminalWidth(outputMd)
-	if err != nil {
-		return judgeResult, err
-	}
-	if output != "" {
-		fmt.Print(out)
-	}
-
-	code, err := os.ReadFile(filePath)
-	if err != nil {
-		return judgeResult, err
-	}
-
-	s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	s.Suffix = " Judging..."
-	s.Start()
-	defer s.Stop()
-
-	code_s := string(code)
-
-	response, err := llm.GenerateJudgement(lesson.TaskDescription, code_s, output, lesson.CorrectOutput, course.Title, lesson.Title)
-	s.Stop()
-	if err != nil {
-		return judgeResult, err
-	}
-
-	err = json.Unmarshal([]byte(response), &judgeResult)
-	if err != nil {
-		return judgeResult, err
-	}
-
-	return judgeResult, nil
-
-}
-
-func run(language, filePath string) (string, error) {
-	commands := map[string][]string{
-		"go":  {"go", "run"},  // Go
-		"py":  getPythonCmd(), // Python
-		"js":  {"node"},       // JavaScrip
-		"ts":  {"tsx"},        // TypeScript
-		"rb":  {"ruby"},       // Ruby
-		"php": {"php"},        // PHP
-	}
-
-	if language == "html" {
-		browser.OpenFile(filePath)
-		return "Opened in browser", nil
-	}
-
-	args, ok := commands[language]
-	if !ok {
-		return "", nil
-	}
-
-	finalArgs := append(args, filePath)
-
-	cmd := exec.Command(finalArgs[0], finalArgs[1:]...)
-
-	output, err := cmd.CombinedOutput()
-	return string(output), err
-}
-
-func getPythonCmd() []string {
-	if runtime.GOOS == "windows" {
-		return []string{"py", "-3"}
-	}
-	return []string{"python3"}
+	return tui.Run(ctx, tui.Options{
+		Course:       c,
+		CoursePath:   coursePath,
+		ProgressPath: progressPath,
+		StartLesson:  start,
+		Watch:        opts.watch,
+	})
 }
 
 func init() {
@@ -355,6 +148,7 @@ func init() {
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	// startCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	startCmd.Flags().BoolP("watch", "w", false, "Run the local checks every time the lesson file is saved")
+	startCmd.Flags().StringP("lesson", "l", "", "Open the lesson with this ID")
 
 }
@@ -0,0 +1,246 @@
+/*
+Copyright © 2026 minotto
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/minotto165/progoat/internal/usage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type usageTotal struct {
+	calls            int
+	promptTokens     int
+	completionTokens int
+	cost             float64
+	unpriced         bool
+}
+
+func (t *usageTotal) add(e usage.Entry, price usage.Price, priced bool) {
+	t.calls++
+	t.promptTokens += e.PromptTokens
+	t.completionTokens += e.CompletionTokens
+	if priced {
+		t.cost += price.Cost(e)
+	} else {
+		t.unpriced = true
+	}
+}
+
+func (t usageTotal) costString() string {
+	if t.unpriced && t.cost == 0 {
+		return "-"
+	}
+	s := fmt.Sprintf("$%.4f", t.cost)
+	if t.unpriced {
+		s += "*"
+	}
+	return s
+}
+
+// saveUsage appends the usage recorded by a command to the ledger. It runs
+// deferred, so a failure can only be reported.
+func saveUsage(recorder *usage.Recorder) {
+	if err := recorder.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not save the token usage:", err)
+	}
+}
+
+// usageCmd represents the usage command
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and estimated cost",
+	Long: `Summarize the tokens used by course generation and judging, by provider and model,
+by command and by course.
+Costs are estimated from the price table ("prices") in config.yaml.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		courseFilter, _ := cmd.Flags().GetString("course")
+
+		var from, to time.Time
+		var err error
+		if fromFlag != "" {
+			from, err = time.ParseInLocation(time.DateOnly, fromFlag, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --from date (use YYYY-MM-DD): %w", err)
+			}
+		}
+		if toFlag != "" {
+			to, err = time.ParseInLocation(time.DateOnly, toFlag, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --to date (use YYYY-MM-DD): %w", err)
+			}
+			to = to.AddDate(0, 0, 1) // 終了日を含める
+		}
+
+		var prices []usage.Price
+		if err := viper.UnmarshalKey("prices", &prices); err != nil {
+			return fmt.Errorf("invalid price table in config: %w", err)
+		}
+		priceOf := map[string]usage.Price{}
+		for _, p := range prices {
+			priceOf[p.Model] = p
+		}
+
+		entries, err := usage.Load(usagePath)
+		if err != nil {
+			return err
+		}
+
+		type modelKey struct{ provider, model string }
+		byModel := map[modelKey]*usageTotal{}
+		byCommand := map[string]*usageTotal{}
+		byCourse := map[string]*usageTotal{}
+		var total usageTotal
+
+		for _, e := range entries {
+			if !from.IsZero() && e.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && !e.Time.Before(to) {
+				continue
+			}
+			if courseFilter != "" && e.CourseID != courseFilter {
+				continue
+			}
+
+			price, priced := priceOf[e.Model]
+
+			k := modelKey{e.Provider, e.Model}
+			if byModel[k] == nil {
+				byModel[k] = &usageTotal{}
+			}
+			byModel[k].add(e, price, priced)
+
+			command := e.Command
+			if command == "" {
+				command = "(none)"
+			}
+			if byCommand[command] == nil {
+				byCommand[command] = &usageTotal{}
+			}
+			byCommand[command].add(e, price, priced)
+
+			courseID := e.CourseID
+			if courseID == "" {
+				courseID = "(none)"
+			}
+			if byCourse[courseID] == nil {
+				byCourse[courseID] = &usageTotal{}
+			}
+			byCourse[courseID].add(e, price, priced)
+
+			total.add(e, price, priced)
+		}
+
+		if total.calls == 0 {
+			fmt.Println("No usage recorded for this period.")
+			return nil
+		}
+
+		nameStyle := lipgloss.NewStyle().Width(30)
+		numStyle := lipgloss.NewStyle().Width(12).Align(lipgloss.Right)
+
+		row := func(name string, t usageTotal) {
+			fmt.Printf("%s %s %s %s %s\n",
+				nameStyle.Render(name),
+				numStyle.Render(fmt.Sprint(t.calls)),
+				numStyle.Render(fmt.Sprint(t.promptTokens)),
+				numStyle.Render(fmt.Sprint(t.completionTokens)),
+				numStyle.Render(t.costString()))
+		}
+		header := func(name string) {
+			fmt.Printf("%s %s %s %s %s\n",
+				nameStyle.Render(name),
+				numStyle.Render("CALLS"),
+				numStyle.Render("INPUT"),
+				numStyle.Render("OUTPUT"),
+				numStyle.Render("COST"))
+		}
+
+		//-----------------
+		// By Model
+		//-----------------
+		fmt.Println("[ By Provider / Model ]")
+		header("PROVIDER/MODEL")
+
+		keys := []modelKey{}
+		for k := range byModel {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].provider != keys[j].provider {
+				return keys[i].provider < keys[j].provider
+			}
+			return keys[i].model < keys[j].model
+		})
+		for _, k := range keys {
+			row(k.provider+"/"+k.model, *byModel[k])
+		}
+		fmt.Print("\n")
+
+		//-----------------
+		// By Command
+		//-----------------
+		fmt.Println("[ By Command ]")
+		header("COMMAND")
+
+		commands := []string{}
+		for c := range byCommand {
+			commands = append(commands, c)
+		}
+		sort.Strings(commands)
+		for _, c := range commands {
+			row(c, *byCommand[c])
+		}
+		fmt.Print("\n")
+
+		//-----------------
+		// By Course
+		//-----------------
+		fmt.Println("[ By Course ]")
+		header("COURSE")
+
+		courseIDs := []string{}
+		for id := range byCourse {
+			courseIDs = append(courseIDs, id)
+		}
+		sort.Strings(courseIDs)
+		for _, id := range courseIDs {
+			row(id, *byCourse[id])
+		}
+		fmt.Print("\n")
+
+		row("TOTAL", total)
+		if total.unpriced {
+			fmt.Println("\n* Some models have no price in config.yaml; their cost is not included.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// usageCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	usageCmd.Flags().String("from", "", "Only include usage on or after this date (YYYY-MM-DD)")
+	usageCmd.Flags().String("to", "", "Only include usage on or before this date (YYYY-MM-DD)")
+	usageCmd.Flags().String("course", "", "Only include usage of this course ID")
+}
@@ -16,13 +16,43 @@ type Course struct {
 }
 
 type Lesson struct {
-	ID              string   `json:"lesson_id"`
-	Title           string   `json:"title"`
-	Slides          []string `json:"slides"`
-	TaskDescription string   `json:"task_description"`
-	InitialCode     string   `json:"initial_code"`
-	CorrectOutput   string   `json:"correct_output"`
-	FileName        string   `json:"file_name"`
+	ID              string     `json:"lesson_id"`
+	Title           string     `json:"title"`
+	Slides          []string   `json:"slides"`
+	TaskDescription string     `json:"task_description"`
+	InitialCode     string     `json:"initial_code"`
+	CorrectOutput   string     `json:"correct_output"`
+	FileName        string     `json:"file_name"`
+	TestCases       []TestCase `json:"test_cases,omitempty"`
+}
+
+type TestCase struct {
+	Stdin          string   `json:"stdin,omitempty"`
+	Args           []string `json:"args,omitempty"`
+	ExpectedOutput string   `json:"expected_output"`
+	Hidden         bool     `json:"hidden,omitempty"` // input and expected output are not shown to the student
+}
+
+// Cases returns the test cases of the lesson. Lessons generated before test
+// cases existed get a single case built from CorrectOutput.
+func (l Lesson) Cases() []TestCase {
+	if len(l.TestCases) > 0 {
+		return l.TestCases
+	}
+	if l.CorrectOutput == "" {
+		return nil
+	}
+	return []TestCase{{ExpectedOutput: l.CorrectOutput}}
+}
+
+// LessonDir returns the directory of a lesson in the course directory.
+func LessonDir(coursePath string, lesson Lesson) string {
+	return filepath.Clean(filepath.Join(coursePath, filepath.Base(lesson.ID)))
+}
+
+// LessonFile returns the path of the file the student edits in a lesson.
+func LessonFile(coursePath string, lesson Lesson) string {
+	return filepath.Clean(filepath.Join(LessonDir(coursePath, lesson), filepath.Base(lesson.FileName)))
 }
 
 func GetCourses(coursesPath string) ([]Course, error) {
@@ -89,9 +119,23 @@ func SaveCourse(response, coursesPath string) (string, error) {
 		return "", fmt.Errorf("failed to parse JSON:%w", err)
 	}
 
+	if err := course.Validate(); err != nil {
+		return "", fmt.Errorf("invalid course:\n%w", err)
+	}
+
+	return WriteCourse(course, coursesPath)
+}
+
+// WriteCourse writes course.json and the initial lesson files without
+// validating the course, so a course saved under older rules can still be
+// reset.
+func WriteCourse(course Course, coursesPath string) (string, error) {
+
 	// Crate course directory
 	coursePath := filepath.Join(coursesPath, filepath.Base(course.ID))
-	os.MkdirAll(coursePath, 0755)
+	if err := os.MkdirAll(coursePath, 0755); err != nil {
+		return "", err
+	}
 
 	// Update courses.json
 	coursesJsonPath := filepath.Join(coursePath, "course.json")
@@ -100,12 +144,19 @@ func SaveCourse(response, coursesPath string) (string, error) {
 		return "", fmt.Errorf("failed to marshal JSON:%w", err)
 	}
 
-	os.WriteFile(coursesJsonPath, coursesJson, 0644)
+	if err := os.WriteFile(coursesJsonPath, coursesJson, 0644); err != nil {
+		return "", err
+	}
 
 	// Create lessons direcotries
 	for _, lesson := range course.Lessons {
+		if lesson.FileName == "" {
+			return "", fmt.Errorf("lesson '%s' has no file_name", lesson.ID)
+		}
 		lessonPath := filepath.Join(coursePath, filepath.Base(lesson.ID))
-		os.MkdirAll(lessonPath, 0755)
+		if err := os.MkdirAll(lessonPath, 0755); err != nil {
+			return "", err
+		}
 
 		// Create slides - not used
 		// slides := lesson.Slides
@@ -116,8 +167,12 @@ func SaveCourse(response, coursesPath string) (string, error) {
 
 		// Write Files
 		// os.WriteFile(filepath.Join(lessonPath, "slide.json"), slidesContent, 0644)
-		os.WriteFile(filepath.Join(lessonPath, "task.md"), []byte(lesson.TaskDescription), 0644)
-		os.WriteFile(filepath.Join(lessonPath, filepath.Base(lesson.FileName)), []byte(lesson.InitialCode), 0644)
+		if err := os.WriteFile(filepath.Join(lessonPath, "task.md"), []byte(lesson.TaskDescription), 0644); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(lessonPath, filepath.Base(lesson.FileName)), []byte(lesson.InitialCode), 0644); err != nil {
+			return "", err
+		}
 
 	}
 	return course.Title, nil
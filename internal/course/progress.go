@@ -9,11 +9,25 @@ import (
 )
 
 type Progress struct {
-	CourseID         string    `json:"course_id"`
-	CompletedLessons []string  `json:"completed_lessons"`
-	CurrentLesson    string    `json:"current_lesson"`
-	LastAccessed     time.Time `json:"last_accessed"`
-	TotalLessons     int       `json:"total_lessons"`
+	CourseID         string           `json:"course_id"`
+	CompletedLessons []string         `json:"completed_lessons"`
+	CurrentLesson    string           `json:"current_lesson"`
+	LastAccessed     time.Time        `json:"last_accessed"`
+	TotalLessons     int              `json:"total_lessons"`
+	Hints            map[string]int   `json:"hints,omitempty"`             // lesson ID -> number of hints shown
+	FailedAttempts   map[string]int   `json:"failed_attempts,omitempty"`   // lesson ID -> number of wrong submissions
+	SolutionRevealed []string         `json:"solution_revealed,omitempty"` // lessons whose reference solution was shown
+	Scores           map[string]Score `json:"scores,omitempty"`            // lesson ID -> rubric score of the latest AI judgement
+}
+
+// Score rates a submission from 0 to 100 on each criterion of the judge's
+// rubric.
+type Score struct {
+	Correctness int `json:"correctness"`
+	Style       int `json:"style"`
+	Idiomatic   int `json:"idiomatic"`  // idiomatic use of the language
+	EdgeCases   int `json:"edge_cases"` // handling of edge cases
+	Overall     int `json:"overall"`
 }
 
 type ProgressStatus int
@@ -25,6 +39,66 @@ const (
 )
 
 func SaveProgress(courseID, completedLessonID, currentLessonID, progressPath string, totalLessons int) error {
+	return updateProgress(courseID, progressPath, totalLessons, func(p *Progress) {
+		// レッスン完了済みは除外
+		if !slices.Contains(p.CompletedLessons, completedLessonID) {
+			p.CompletedLessons = append(p.CompletedLessons, completedLessonID)
+		}
+		p.CurrentLesson = currentLessonID
+	})
+}
+
+// RecordHint counts a hint shown in a lesson and returns how many hints the
+// lesson has used.
+func RecordHint(courseID, lessonID, progressPath string, totalLessons int) (int, error) {
+	used := 0
+	err := updateProgress(courseID, progressPath, totalLessons, func(p *Progress) {
+		if p.Hints == nil {
+			p.Hints = map[string]int{}
+		}
+		p.Hints[lessonID]++
+		used = p.Hints[lessonID]
+	})
+	return used, err
+}
+
+// RecordFailedAttempt counts a wrong submission in a lesson and returns how
+// many the lesson has had.
+func RecordFailedAttempt(courseID, lessonID, progressPath string, totalLessons int) (int, error) {
+	failed := 0
+	err := updateProgress(courseID, progressPath, totalLessons, func(p *Progress) {
+		if p.FailedAttempts == nil {
+			p.FailedAttempts = map[string]int{}
+		}
+		p.FailedAttempts[lessonID]++
+		failed = p.FailedAttempts[lessonID]
+	})
+	return failed, err
+}
+
+// RecordSolutionRevealed notes that the reference solution of a lesson was
+// shown, so completing it counts as completed with the solution revealed.
+func RecordSolutionRevealed(courseID, lessonID, progressPath string, totalLessons int) error {
+	return updateProgress(courseID, progressPath, totalLessons, func(p *Progress) {
+		if !slices.Contains(p.SolutionRevealed, lessonID) {
+			p.SolutionRevealed = append(p.SolutionRevealed, lessonID)
+		}
+	})
+}
+
+// RecordScore keeps the rubric score of the latest AI judgement of a lesson.
+func RecordScore(courseID, lessonID, progressPath string, totalLessons int, score Score) error {
+	return updateProgress(courseID, progressPath, totalLessons, func(p *Progress) {
+		if p.Scores == nil {
+			p.Scores = map[string]Score{}
+		}
+		p.Scores[lessonID] = score
+	})
+}
+
+// updateProgress applies update to the progress of a course, creating it if
+// the course has not been started, and saves it.
+func updateProgress(courseID, progressPath string, totalLessons int, update func(*Progress)) error {
 
 	progresses, err := LoadProgresses(progressPath)
 	if err != nil {
@@ -43,16 +117,11 @@ func SaveProgress(courseID, completedLessonID, currentLessonID, progressPath str
 
 	// コース未開始の場合
 	if idx == -1 {
-		progresses = append(progresses, Progress{courseID, []string{}, "", time.Now(), totalLessons})
+		progresses = append(progresses, Progress{CourseID: courseID, CompletedLessons: []string{}, LastAccessed: time.Now(), TotalLessons: totalLessons})
 		idx = len(progresses) - 1
 	}
 
-	// レッスン完了済みは除外
-	if !slices.Contains(progresses[idx].CompletedLessons, completedLessonID) {
-		progresses[idx].CompletedLessons = append(progresses[idx].CompletedLessons, completedLessonID)
-	}
-
-	progresses[idx].CurrentLesson = currentLessonID
+	update(&progresses[idx])
 	progresses[idx].LastAccessed = time.Now()
 	progresses[idx].TotalLessons = totalLessons
 
@@ -127,6 +196,21 @@ func LoadProgressStatus(courseID, progressPath string) (ProgressStatus, string,
 	return NotStarted, "", nil
 }
 
+// LoadProgress returns the progress of a course, which is empty if the course
+// has not been started.
+func LoadProgress(courseID, progressPath string) (Progress, error) {
+	progresses, err := LoadProgresses(progressPath)
+	if err != nil {
+		return Progress{}, err
+	}
+	for _, p := range progresses {
+		if p.CourseID == courseID {
+			return p, nil
+		}
+	}
+	return Progress{CourseID: courseID}, nil
+}
+
 func LoadProgresses(progressPath string) ([]Progress, error) {
 	progressJson, err := os.ReadFile(progressPath)
 	if err != nil {
@@ -0,0 +1,155 @@
+package course
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors lists every problem found in a course.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := []string{}
+	for _, v := range e {
+		lines = append(lines, fmt.Sprintf("%s: %s", v.Field, v.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationErrors) add(field, format string, args ...any) {
+	*e = append(*e, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+var reID = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+var reExtension = regexp.MustCompile(`^[a-z0-9]{1,10}$`)
+
+// Natural languages the model sometimes puts in programming_language.
+var naturalLanguages = []string{
+	"ja", "en", "zh", "ko", "fr", "de", "es", "it", "pt", "ru", "vi", "th", "id",
+	"japanese", "english", "chinese", "korean", "french", "german", "spanish",
+}
+
+func validateID(errs *ValidationErrors, field, id string) {
+	switch {
+	case id == "":
+		errs.add(field, "is required")
+	case id == "." || id == ".." || !reID.MatchString(id):
+		errs.add(field, "must only contain letters, digits, '.', '_' and '-' (got %q)", id)
+	}
+}
+
+// Validate reports every problem that would produce a broken course directory.
+// The returned error is a ValidationErrors.
+func (c Course) Validate() error {
+	errs := c.outlineErrors()
+	for i, l := range c.Lessons {
+		for _, e := range l.contentErrors(c.ProgrammingLanguage) {
+			errs.add(fmt.Sprintf("lessons[%d].%s", i, e.Field), "%s", e.Message)
+		}
+	}
+	return errs.err()
+}
+
+// ValidateOutline checks the course metadata and the lesson IDs and titles,
+// ignoring the content of the lessons.
+func (c Course) ValidateOutline() error {
+	return c.outlineErrors().err()
+}
+
+// ValidateContent checks the slides, task and code file of a lesson.
+func (l Lesson) ValidateContent(language string) error {
+	return l.contentErrors(language).err()
+}
+
+func (c Course) outlineErrors() ValidationErrors {
+	var errs ValidationErrors
+
+	validateID(&errs, "course_id", c.ID)
+	if strings.TrimSpace(c.Title) == "" {
+		errs.add("title", "is required")
+	}
+
+	lang := c.ProgrammingLanguage
+	switch {
+	case lang == "":
+		errs.add("programming_language", "is required")
+	case slices.Contains(naturalLanguages, strings.ToLower(lang)):
+		errs.add("programming_language", "must be a file extension such as go, py or js, not a natural language (got %q)", lang)
+	case !reExtension.MatchString(lang):
+		errs.add("programming_language", "must be a lowercase file extension such as go, py or js (got %q)", lang)
+	}
+
+	if len(c.Lessons) == 0 {
+		errs.add("lessons", "must contain at least one lesson")
+	}
+
+	seen := map[string]bool{}
+	for i, l := range c.Lessons {
+		field := fmt.Sprintf("lessons[%d]", i)
+		validateID(&errs, field+".lesson_id", l.ID)
+		if seen[l.ID] {
+			errs.add(field+".lesson_id", "duplicates another lesson (%q)", l.ID)
+		}
+		seen[l.ID] = true
+		if strings.TrimSpace(l.Title) == "" {
+			errs.add(field+".title", "is required")
+		}
+	}
+
+	return errs
+}
+
+func (l Lesson) contentErrors(language string) ValidationErrors {
+	var errs ValidationErrors
+
+	if len(l.Slides) == 0 {
+		errs.add("slides", "must contain at least one slide")
+	}
+	for i, s := range l.Slides {
+		if strings.TrimSpace(s) == "" {
+			errs.add(fmt.Sprintf("slides[%d]", i), "is empty")
+		}
+	}
+	if strings.TrimSpace(l.TaskDescription) == "" {
+		errs.add("task_description", "is required")
+	}
+	if strings.TrimSpace(l.InitialCode) == "" {
+		errs.add("initial_code", "is required")
+	}
+
+	for i, tc := range l.TestCases {
+		if tc.ExpectedOutput == "" {
+			errs.add(fmt.Sprintf("test_cases[%d].expected_output", i), "is required")
+		}
+	}
+	// 実行結果の表示や履歴には最初のケースの出力を使うので、隠しケースにしない
+	if len(l.TestCases) > 0 && l.TestCases[0].Hidden {
+		errs.add("test_cases[0].hidden", "must be false: the output of the first test case is shown to the student")
+	}
+
+	switch {
+	case l.FileName == "":
+		errs.add("file_name", "is required")
+	case filepath.Base(l.FileName) != l.FileName || l.FileName == "." || l.FileName == "..":
+		errs.add("file_name", "must be a plain file name without directories (got %q)", l.FileName)
+	case language != "" && filepath.Ext(l.FileName) != "."+language:
+		errs.add("file_name", "must have the extension .%s (got %q)", language, l.FileName)
+	}
+
+	return errs
+}
@@ -0,0 +1,79 @@
+package course
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validCourse() Course {
+	return Course{
+		ID:                  "go-basics",
+		Title:               "Go Basics",
+		ProgrammingLanguage: "go",
+		Lessons: []Lesson{{
+			ID:              "hello",
+			Title:           "Hello",
+			Slides:          []string{"# Hello"},
+			TaskDescription: "Print hello.",
+			InitialCode:     "package main\n",
+			FileName:        "main.go",
+			TestCases:       []TestCase{{ExpectedOutput: "hello"}},
+		}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Course)
+		fields []string // fields reported, in order; none means valid
+	}{
+		{"valid", func(*Course) {}, nil},
+		{"missing course ID", func(c *Course) { c.ID = "" }, []string{"course_id"}},
+		{"path in course ID", func(c *Course) { c.ID = "../x" }, []string{"course_id"}},
+		{"natural language", func(c *Course) { c.ProgrammingLanguage = "ja" }, []string{"programming_language", "lessons[0].file_name"}},
+		{"no lessons", func(c *Course) { c.Lessons = nil }, []string{"lessons"}},
+		{"duplicate lesson", func(c *Course) { c.Lessons = append(c.Lessons, c.Lessons[0]) }, []string{"lessons[1].lesson_id"}},
+		{"missing file name", func(c *Course) { c.Lessons[0].FileName = "" }, []string{"lessons[0].file_name"}},
+		{"directory in file name", func(c *Course) { c.Lessons[0].FileName = "src/main.go" }, []string{"lessons[0].file_name"}},
+		{"wrong extension", func(c *Course) { c.Lessons[0].FileName = "main.py" }, []string{"lessons[0].file_name"}},
+		{"empty slide", func(c *Course) { c.Lessons[0].Slides = []string{" "} }, []string{"lessons[0].slides[0]"}},
+		{"only hidden cases", func(c *Course) { c.Lessons[0].TestCases[0].Hidden = true }, []string{"lessons[0].test_cases[0].hidden"}},
+		{"hidden first case", func(c *Course) {
+			c.Lessons[0].TestCases[0].Hidden = true
+			c.Lessons[0].TestCases = append(c.Lessons[0].TestCases, TestCase{ExpectedOutput: "hello"})
+		}, []string{"lessons[0].test_cases[0].hidden"}},
+		{"hidden later case", func(c *Course) {
+			c.Lessons[0].TestCases = append(c.Lessons[0].TestCases, TestCase{ExpectedOutput: "bye", Hidden: true})
+		}, nil},
+		{"missing expected output", func(c *Course) { c.Lessons[0].TestCases[0].ExpectedOutput = "" }, []string{"lessons[0].test_cases[0].expected_output"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validCourse()
+			tt.modify(&c)
+
+			err := c.Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v, want ValidationErrors", err)
+			}
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Field)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("fields = %v, want %v\n%v", got, tt.fields, err)
+			}
+		})
+	}
+}
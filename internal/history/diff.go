@@ -0,0 +1,136 @@
+package history
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// Diff returns a unified diff of two submissions, or "" when they are the
+// same.
+func Diff(from, to, fromName, toName string) string {
+	lines := diffLines(from, to)
+	if !slices.ContainsFunc(lines, func(l diffLine) bool { return l.op != ' ' }) {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(lines); {
+		// 次の変更箇所を探し、前後の共通行を含めてひとまとまりにする
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		begin := max(first-diffContext, start)
+		end := first
+		for k := first; k < len(lines); k++ {
+			if lines[k].op != ' ' {
+				end = k + 1
+			} else if k-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(end+diffContext, len(lines))
+
+		writeHunk(&out, lines, begin, end)
+		start = end
+	}
+	return out.String()
+}
+
+// Stat returns the number of lines added and removed between two submissions.
+func Stat(from, to string) (added, removed int) {
+	for _, l := range diffLines(from, to) {
+		switch l.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// diffLines aligns the lines of two submissions. Lesson files are short, so a
+// plain LCS table is enough.
+func diffLines(from, to string) []diffLine {
+	a, b := splitLines(from), splitLines(to)
+
+	// lcs[i][j] は a[i:] と b[j:] の最長共通部分列の長さ
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// writeHunk writes lines[begin:end] with its @@ header.
+func writeHunk(out *strings.Builder, lines []diffLine, begin, end int) {
+	aStart, bStart := 1, 1
+	for _, l := range lines[:begin] {
+		if l.op != '+' {
+			aStart++
+		}
+		if l.op != '-' {
+			bStart++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, l := range lines[begin:end] {
+		if l.op != '+' {
+			aLen++
+		}
+		if l.op != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, l := range lines[begin:end] {
+		fmt.Fprintf(out, "%c%s\n", l.op, l.text)
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
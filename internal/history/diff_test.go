@@ -0,0 +1,86 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"trailing newline only", "a\nb", "a\nb\n", ""},
+		{
+			"changed line", "a\nb\nc\n", "a\nB\nc\n",
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"from empty", "", "a\n",
+			"--- old\n+++ new\n@@ -1,0 +1,1 @@\n+a\n",
+		},
+		{
+			// -- や ++ で始まるコード行も通常の行として扱う
+			"lines starting with -- and ++", "--i;\n", "++i;\n",
+			"--- old\n+++ new\n@@ -1,1 +1,1 @@\n---i;\n+++i;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.from, tt.to, "old", "new"); got != tt.want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffSplitsDistantChanges(t *testing.T) {
+	var from, to []string
+	for i := 1; i <= 30; i++ {
+		from = append(from, fmt.Sprint("line", i))
+		switch i {
+		case 2:
+			to = append(to, "changed")
+		case 20:
+		default:
+			to = append(to, fmt.Sprint("line", i))
+		}
+	}
+
+	got := Diff(strings.Join(from, "\n"), strings.Join(to, "\n"), "old", "new")
+	for _, header := range []string{"@@ -1,5 +1,5 @@", "@@ -17,7 +17,6 @@"} {
+		if !strings.Contains(got, header) {
+			t.Errorf("missing hunk %s in\n%s", header, got)
+		}
+	}
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Errorf("hunks = %d, want 2\n%s", n, got)
+	}
+}
+
+func TestStat(t *testing.T) {
+	tests := []struct {
+		name           string
+		from, to       string
+		added, removed int
+	}{
+		{"same", "a\nb\n", "a\nb\n", 0, 0},
+		{"changed line", "a\nb\nc\n", "a\nB\nc\n", 1, 1},
+		{"from empty", "", "a\nb\n", 2, 0},
+		// 描画済みの diff を数え直すと "--- " "+++ " の見出しと区別できない
+		{"lines starting with -- and ++", "--i;\nx\n", "++i;\n", 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := Stat(tt.from, tt.to)
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("Stat() = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minotto165/progoat/internal/course"
+)
+
+// historyDir keeps the attempts in the course directory, next to the
+// lessons: .history/<lesson ID>/attempts.jsonl and one snapshot per distinct
+// submitted code.
+const (
+	historyDir   = ".history"
+	attemptsFile = "attempts.jsonl"
+)
+
+// Attempt is one judged submission of a lesson.
+type Attempt struct {
+	Time      time.Time     `json:"time"`
+	CodeHash  string        `json:"code_hash"` // SHA-256 of the submitted code
+	CodeFile  string        `json:"code_file"` // snapshot of the code, relative to the lesson's history directory
+	Output    string        `json:"output"`
+	IsCorrect bool          `json:"is_correct"`
+	Score     *course.Score `json:"score,omitempty"` // the AI judge's rubric score, if it was asked
+	Advice    string        `json:"advice,omitempty"`
+}
+
+func lessonDir(coursePath string, lesson course.Lesson) string {
+	return filepath.Join(coursePath, historyDir, filepath.Base(lesson.ID))
+}
+
+// Record appends an attempt of the lesson and saves a snapshot of its code.
+// The time and the code fields of a are filled in. Identical code is stored
+// once.
+func Record(coursePath string, lesson course.Lesson, code []byte, a Attempt) error {
+	dir := lessonDir(coursePath, lesson)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(code)
+	a.Time = time.Now()
+	a.CodeHash = hex.EncodeToString(sum[:])
+	a.CodeFile = a.CodeHash[:12] + filepath.Ext(lesson.FileName)
+
+	snapshot := filepath.Join(dir, a.CodeFile)
+	if _, err := os.Stat(snapshot); os.IsNotExist(err) {
+		if err := os.WriteFile(snapshot, code, 0644); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, attemptsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load returns the attempts of the lesson, oldest first.
+func Load(coursePath string, lesson course.Lesson) ([]Attempt, error) {
+	f, err := os.Open(filepath.Join(lessonDir(coursePath, lesson), attemptsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Attempt{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	attempts := []Attempt{}
+	scanner := bufio.NewScanner(f)
+	// 実行結果が長い行もあるので、出力の上限より大きくしておく
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var a Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			continue // 壊れた行は無視
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, scanner.Err()
+}
+
+// Code returns the code submitted in the attempt.
+func Code(coursePath string, lesson course.Lesson, a Attempt) (string, error) {
+	data, err := os.ReadFile(filepath.Join(lessonDir(coursePath, lesson), filepath.Base(a.CodeFile)))
+	if err != nil {
+		return "", fmt.Errorf("snapshot of the attempt at %s is missing: %w", a.Time.Format(time.DateTime), err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,130 @@
+package judge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minotto165/progoat/internal/course"
+)
+
+// Whitespace handling when comparing outputs.
+const (
+	WhitespaceExact    = "exact"    // only line endings are normalized
+	WhitespaceTrailing = "trailing" // trailing spaces and blank lines are ignored
+	WhitespaceAll      = "all"      // every run of whitespace counts as one space
+)
+
+func ValidateWhitespace(mode string) error {
+	switch mode {
+	case WhitespaceExact, WhitespaceTrailing, WhitespaceAll:
+		return nil
+	}
+	return fmt.Errorf("invalid whitespace mode '%s' (exact, trailing, all)", mode)
+}
+
+// Normalize prepares an output for comparison. Unknown modes behave like
+// WhitespaceTrailing.
+func Normalize(s, mode string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	switch mode {
+	case WhitespaceExact:
+		return s
+	case WhitespaceAll:
+		return strings.Join(strings.Fields(s), " ")
+	default:
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	}
+}
+
+// OutputMatches reports whether the actual output equals the expected one
+// after normalization.
+func OutputMatches(actual, expected, mode string) bool {
+	return Normalize(actual, mode) == Normalize(expected, mode)
+}
+
+// Phases of running a lesson program.
+const (
+	PhaseBuild = "build"
+	PhaseRun   = "run"
+)
+
+// Result is the outcome of one test case. When the build fails, every case
+// carries the build's result.
+type Result struct {
+	Case     course.TestCase
+	Phase    string
+	Output   string // stdout
+	Stderr   string
+	ExitCode int
+	Limit    string // the resource limit that stopped the program, if any
+	Passed   bool
+}
+
+// Failure describes why the program did not finish normally, or returns "".
+func (r Result) Failure() string {
+	switch {
+	case r.Limit != "":
+		return r.Limit
+	case r.Phase == PhaseBuild && r.ExitCode != 0:
+		return fmt.Sprintf("compile error (exit status %d)", r.ExitCode)
+	case r.ExitCode != 0:
+		return fmt.Sprintf("runtime error (exit status %d)", r.ExitCode)
+	}
+	return ""
+}
+
+func AllPassed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// Summary describes the results for the AI judge. Inputs and outputs of
+// hidden cases are left out so they cannot leak into the advice.
+func Summary(results []Result) string {
+	passed := 0
+	for _, r := range results {
+		if r.Passed {
+			passed++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%d test cases passed.\n", passed, len(results))
+	for i, r := range results {
+		status := "passed"
+		if !r.Passed {
+			status = "FAILED"
+		}
+		if failure := r.Failure(); failure != "" {
+			status += " (" + failure + ")"
+		}
+		if r.Case.Hidden {
+			fmt.Fprintf(&b, "Case %d (hidden): %s\n", i+1, status)
+			continue
+		}
+		fmt.Fprintf(&b, "Case %d: %s\n", i+1, status)
+		if r.Passed {
+			continue
+		}
+		if len(r.Case.Args) > 0 {
+			fmt.Fprintf(&b, "  args: %s\n", strings.Join(r.Case.Args, " "))
+		}
+		if r.Case.Stdin != "" {
+			fmt.Fprintf(&b, "  stdin: %q\n", r.Case.Stdin)
+		}
+		fmt.Fprintf(&b, "  expected: %q\n  actual: %q\n", r.Case.ExpectedOutput, r.Output)
+		if r.Stderr != "" {
+			fmt.Fprintf(&b, "  stderr: %q\n", r.Stderr)
+		}
+	}
+	return b.String()
+}
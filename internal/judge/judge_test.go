@@ -0,0 +1,42 @@
+package judge
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, mode, want string
+	}{
+		{"a\r\nb\r\n", WhitespaceExact, "a\nb\n"},
+		{"a  \nb\t\n\n\n", WhitespaceTrailing, "a\nb"},
+		{"  a\n", WhitespaceTrailing, "  a"},
+		{" a \n\t b  c\n", WhitespaceAll, "a b c"},
+		{"a \n\n", "unknown", "a"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.mode); got != tt.want {
+			t.Errorf("Normalize(%q, %s) = %q, want %q", tt.in, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestOutputMatches(t *testing.T) {
+	tests := []struct {
+		actual, expected, mode string
+		want                   bool
+	}{
+		{"55\n", "55", WhitespaceTrailing, true},
+		{"55\r\n", "55\n", WhitespaceExact, true},
+		{"55\n", "55", WhitespaceExact, false},
+		{"1 2\n3", "1\n2 3", WhitespaceAll, true},
+		{"1 2\n3", "1\n2 3", WhitespaceTrailing, false},
+		{" 55", "55", WhitespaceTrailing, false},
+		{"54", "55", WhitespaceAll, false},
+	}
+
+	for _, tt := range tests {
+		if got := OutputMatches(tt.actual, tt.expected, tt.mode); got != tt.want {
+			t.Errorf("OutputMatches(%q, %q, %s) = %v, want %v", tt.actual, tt.expected, tt.mode, got, tt.want)
+		}
+	}
+}
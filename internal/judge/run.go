@@ -0,0 +1,146 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/runner"
+	"github.com/pkg/browser"
+	"github.com/spf13/viper"
+)
+
+// WhitespaceFromConfig returns judge.whitespace from config.yaml, which
+// defaults to WhitespaceTrailing.
+func WhitespaceFromConfig() (string, error) {
+	whitespace := viper.GetString("judge.whitespace")
+	if whitespace == "" {
+		whitespace = WhitespaceTrailing
+	}
+	return whitespace, ValidateWhitespace(whitespace)
+}
+
+// Cases returns the test cases to run. Without test cases, the program is
+// run once and only the AI judges it.
+func Cases(lesson course.Lesson) []course.TestCase {
+	cases := lesson.Cases()
+	if len(cases) == 0 {
+		cases = []course.TestCase{{}}
+	}
+	return cases
+}
+
+// Run builds the lesson file once if its language needs it, then runs it for
+// every test case. A failed build is reported for every case.
+func Run(ctx context.Context, lesson course.Lesson, language, filePath string) ([]Result, error) {
+	whitespace, err := WhitespaceFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	cases := Cases(lesson)
+
+	results := []Result{}
+	note := func(res runner.Result, phase string) []Result {
+		for _, tc := range cases {
+			results = append(results, Result{
+				Case:     tc,
+				Phase:    phase,
+				Output:   res.Stdout,
+				Stderr:   res.Stderr,
+				ExitCode: res.ExitCode,
+				Limit:    res.Limit,
+			})
+		}
+		return results
+	}
+
+	if language == "html" {
+		browser.OpenFile(filePath)
+		return note(runner.Result{Stdout: "Opened in browser"}, PhaseRun), nil
+	}
+
+	lang, ok, err := runner.LookupLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return note(runner.Result{Stdout: fmt.Sprintf("(No runner for .%s files. Add one under runner.languages in config.yaml.)", language)}, PhaseRun), nil
+	}
+	lang, err = lang.Expand(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := runner.FromConfig(language)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lang.Build) > 0 {
+		res, err := run(ctx, r, lang.Build, filePath, "")
+		if err != nil {
+			return nil, err
+		}
+		if res.ExitCode != 0 || res.Limit != "" {
+			return note(res, PhaseBuild), nil
+		}
+	}
+
+	for _, tc := range cases {
+		res, err := run(ctx, r, slices.Concat(lang.Run, tc.Args), filePath, tc.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, Result{
+			Case:     tc,
+			Phase:    PhaseRun,
+			Output:   res.Stdout,
+			Stderr:   res.Stderr,
+			ExitCode: res.ExitCode,
+			Limit:    res.Limit,
+			Passed: tc.ExpectedOutput != "" && res.ExitCode == 0 && res.Limit == "" &&
+				OutputMatches(res.Stdout, tc.ExpectedOutput, whitespace),
+		})
+	}
+	return results, nil
+}
+
+// run runs one step in the directory of the lesson file. Errors are only
+// returned when the step could not be run at all.
+func run(ctx context.Context, r runner.Runner, args []string, filePath, stdin string) (runner.Result, error) {
+	return r.Run(ctx, runner.Command{
+		Args:  args,
+		Dir:   filepath.Dir(filePath),
+		Stdin: stdin,
+	})
+}
+
+// Status summarizes the local checks in one line. passed is true when the
+// lesson has test cases and all of them passed.
+func Status(lesson course.Lesson, results []Result) (passed bool, status string) {
+	hasTests := len(lesson.Cases()) > 0
+	failure := ""
+	count := 0
+	for _, r := range results {
+		if r.Passed {
+			count++
+		}
+		if failure == "" {
+			failure = r.Failure()
+		}
+	}
+
+	switch {
+	case hasTests && count == len(results):
+		return true, fmt.Sprintf("✅ All %d test cases passed", len(results))
+	case hasTests && failure != "":
+		return false, fmt.Sprintf("❌ %d/%d test cases passed (%s)", count, len(results), failure)
+	case hasTests:
+		return false, fmt.Sprintf("❌ %d/%d test cases passed", count, len(results))
+	case failure != "":
+		return false, "❌ " + failure
+	}
+	return false, "✔ Ran without errors"
+}
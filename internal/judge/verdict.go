@@ -0,0 +1,98 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/llm"
+	"github.com/spf13/viper"
+)
+
+// DefaultPassThreshold is the overall score a submission needs to pass the
+// AI judge, configurable under judge.pass_threshold.
+const DefaultPassThreshold = 70
+
+// Verdict is the judgement of one submission.
+type Verdict struct {
+	Results       []Result      `json:"-"`
+	IsCorrect     bool          `json:"-"`
+	Score         *course.Score `json:"score"` // the AI's rubric score; nil when judged by the test cases alone
+	Advice        string        `json:"advice"`
+	OutputMatched bool          `json:"-"` // every test case passed
+}
+
+// PassThreshold returns judge.pass_threshold from config.yaml.
+func PassThreshold() int {
+	if viper.IsSet("judge.pass_threshold") {
+		return min(max(viper.GetInt("judge.pass_threshold"), 0), 100)
+	}
+	return DefaultPassThreshold
+}
+
+// Local judges the results without the AI: passing every test case is
+// correct. ok is false when the AI must be asked, which is also the case when
+// judge.advice_on_match is set.
+func Local(lesson course.Lesson, results []Result) (v Verdict, ok bool) {
+	v = Verdict{Results: results}
+	if len(lesson.Cases()) == 0 || !AllPassed(results) {
+		return v, false
+	}
+	v.IsCorrect = true
+	v.OutputMatched = true
+	return v, !viper.GetBool("judge.advice_on_match")
+}
+
+// AskAI asks the AI judge to score the submission and give advice. The test
+// cases are authoritative: passing all of them is correct and failing any is
+// wrong. The pass threshold only decides lessons without test cases. A
+// response without a score is an error, so the student can judge again.
+func AskAI(ctx context.Context, c course.Course, lesson course.Lesson, filePath string, results []Result) (Verdict, error) {
+	v := Verdict{Results: results}
+
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return v, err
+	}
+
+	first := results[0]
+	execution := llm.Execution{
+		Phase:    first.Phase,
+		ExitCode: first.ExitCode,
+		Stdout:   first.Output,
+		Stderr:   first.Stderr,
+		Limit:    first.Limit,
+	}
+	hasTests := len(lesson.Cases()) > 0
+	if hasTests {
+		execution.TestResults = Summary(results)
+	}
+
+	response, err := llm.GenerateJudgement(ctx, lesson.TaskDescription, string(code), execution, lesson.CorrectOutput, c.Title, lesson.Title)
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal([]byte(response), &v); err != nil {
+		return v, err
+	}
+	if v.Score == nil {
+		return v, errors.New("the AI judge returned no score")
+	}
+	// モデルが範囲外の値を返すことがあるので丸めておく
+	for _, n := range []*int{&v.Score.Correctness, &v.Score.Style, &v.Score.Idiomatic, &v.Score.EdgeCases, &v.Score.Overall} {
+		*n = min(max(*n, 0), 100)
+	}
+
+	switch {
+	case hasTests && AllPassed(results):
+		v.IsCorrect = true
+		v.OutputMatched = true
+	case hasTests:
+		v.IsCorrect = false
+	default:
+		v.IsCorrect = v.Score.Overall >= PassThreshold()
+	}
+	return v, nil
+}
@@ -0,0 +1,51 @@
+package judge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/spf13/viper"
+)
+
+// The fake judge in docs/fixtures scores every submission 90.
+func TestAskAILetsTestCasesDecide(t *testing.T) {
+	viper.Set("active_provider", "fake")
+	viper.Set("providers.fake.fixtures", filepath.Join("..", "..", "docs", "fixtures"))
+	t.Cleanup(viper.Reset)
+
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withTests := course.Lesson{TestCases: []course.TestCase{{ExpectedOutput: "55"}, {ExpectedOutput: "10", Hidden: true}}}
+	withoutTests := course.Lesson{}
+
+	tests := []struct {
+		name      string
+		lesson    course.Lesson
+		results   []Result
+		threshold int
+		want      bool
+	}{
+		{"all tests passed", withTests, []Result{{Passed: true}, {Passed: true}}, 95, true},
+		{"hidden test failed", withTests, []Result{{Passed: true}, {Passed: false}}, 70, false},
+		{"no tests, score reaches threshold", withoutTests, []Result{{}}, 90, true},
+		{"no tests, score below threshold", withoutTests, []Result{{}}, 95, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("judge.pass_threshold", tt.threshold)
+			v, err := AskAI(context.Background(), course.Course{}, tt.lesson, filePath, tt.results)
+			if err != nil {
+				t.Fatalf("AskAI: %v", err)
+			}
+			if v.IsCorrect != tt.want {
+				t.Errorf("IsCorrect = %v, want %v (score %+v)", v.IsCorrect, tt.want, v.Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/charmbracelet/huh"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/mozilla-ai/any-llm-go/providers/anthropic"
+	"github.com/mozilla-ai/any-llm-go/providers/gemini"
+	"github.com/mozilla-ai/any-llm-go/providers/openai"
+	"github.com/mozilla-ai/any-llm-go/providers/zai"
+)
+
+// Built-in backends. This file sorts first so they are listed before the
+// backends registered by other files.
+func init() {
+	Register(Backend{
+		ID:   "gemini",
+		Name: "Gemini",
+		New: func(s Settings) (Provider, error) {
+			return gemini.New(anyllm.WithAPIKey(s.APIKey))
+		},
+		// フォールバック
+		DefaultModels: []huh.Option[string]{
+			huh.NewOption("Gemini 3.1 Pro Preview", "gemini-3.1-pro-preview"),
+			huh.NewOption("Gemini 3 Flash Preview", "gemini-3-flash-preview"),
+			huh.NewOption("Gemini Flash Latest", "gemini-flash-latest"),
+			huh.NewOption("Gemini Flash Lite Latest", "gemini-flash-lite-latest"),
+			huh.NewOption("Gemini 2.5 Pro", "gemini-2.5-pro"),
+		},
+		FetchModels: func(ctx context.Context, s Settings) ([]huh.Option[string], error) {
+			return FetchGeminiModels(ctx, s.APIKey)
+		},
+		ValidateKey: requireKey,
+	})
+
+	Register(Backend{
+		ID:   "openai",
+		Name: "OpenAI",
+		New: func(s Settings) (Provider, error) {
+			return openai.New(anyllm.WithAPIKey(s.APIKey))
+		},
+		DefaultModels: []huh.Option[string]{
+			huh.NewOption("GPT-5.4", "gpt-5.4"),
+			huh.NewOption("GPT-5 mini", "gpt-5-mini"),
+			huh.NewOption("GPT-5 nano", "gpt-5-nano"),
+		},
+		ValidateKey: requireKeyPrefix("sk-"),
+	})
+
+	Register(Backend{
+		ID:   "anthropic",
+		Name: "Anthropic",
+		New: func(s Settings) (Provider, error) {
+			return anthropic.New(anyllm.WithAPIKey(s.APIKey))
+		},
+		DefaultModels: []huh.Option[string]{
+			huh.NewOption("Claude Opus 4.6", "claude-opus-4-6"),
+			huh.NewOption("Claude Sonnet 4.6", "claude-sonnet-4-6"),
+			huh.NewOption("Claude Haiku 4.5", "claude-haiku-4-5-20251001"),
+		},
+		ValidateKey: requireKeyPrefix("sk-ant-"),
+	})
+
+	Register(Backend{
+		ID:   "zai",
+		Name: "Z.AI",
+		New: func(s Settings) (Provider, error) {
+			return zai.New(anyllm.WithAPIKey(s.APIKey))
+		},
+		DefaultModels: []huh.Option[string]{
+			huh.NewOption("GLM-5", "glm-5"),
+			huh.NewOption("GLM-4.7", "glm-4.7"),
+			huh.NewOption("GLM-4.7-FlashX", "glm-4.7-flashx"),
+			huh.NewOption("GLM-4.7-Flash", "glm-4.7-flash"),
+		},
+		ValidateKey: requireKey,
+	})
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+)
+
+// Roles of a ChatMessage.
+const (
+	ChatUser      = "user"
+	ChatAssistant = "assistant"
+)
+
+// ChatMessage is one turn of a tutor conversation.
+type ChatMessage struct {
+	Role    string    `json:"role"` // ChatUser or ChatAssistant
+	Content string    `json:"content"`
+	Time    time.Time `json:"time"`
+}
+
+// LessonContext is what the tutor knows about the lesson the student is on.
+type LessonContext struct {
+	CourseTitle string
+	LessonTitle string
+	Slides      []string
+	Task        string
+	Code        string
+	LastRun     string // output of the last run, empty if the code has not been run
+}
+
+// GenerateAnswer answers the last user message of history as a tutor for the
+// lesson. The lesson is given first, so it also covers earlier turns.
+func GenerateAnswer(ctx context.Context, lesson LessonContext, history []ChatMessage) (string, error) {
+	provider, activeModel, err := activeProvider("judge_model")
+	if err != nil {
+		return "", err
+	}
+
+	lastRun := lesson.LastRun
+	if lastRun == "" {
+		lastRun = "(not run yet)"
+	}
+
+	messages := []anyllm.Message{
+		{
+			Role:    anyllm.RoleSystem,
+			Content: `You are a friendly programming tutor answering questions about one lesson of a course. Explain concepts and point the student to the cause of their problems, but do not write the complete solution to the task; let the student write it. Keep answers short and answer in the student's language using Markdown.`,
+		},
+		{Role: anyllm.RoleUser, Content: "Course Title:" + lesson.CourseTitle},
+		{Role: anyllm.RoleUser, Content: "Lesson Title:" + lesson.LessonTitle},
+		{Role: anyllm.RoleUser, Content: "Slides:" + strings.Join(lesson.Slides, "\n---\n")},
+		{Role: anyllm.RoleUser, Content: "Task:" + lesson.Task},
+		{Role: anyllm.RoleUser, Content: "Student Code:" + lesson.Code},
+		{Role: anyllm.RoleUser, Content: "Last Run:" + lastRun},
+	}
+	for _, m := range history {
+		role := anyllm.RoleUser
+		if m.Role == ChatAssistant {
+			role = anyllm.RoleAssistant
+		}
+		messages = append(messages, anyllm.Message{Role: role, Content: m.Content})
+	}
+
+	response, err := complete(ctx, provider, anyllm.CompletionParams{
+		Model:    activeModel,
+		Messages: messages,
+		Tools: []anyllm.Tool{
+			{
+				Type: "function",
+				Function: anyllm.Function{
+					Name: "answer_question",
+					Parameters: map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"answer": map[string]any{"type": "string", "description": "The answer in Markdown, in the student's language."},
+						},
+						"required": []string{"answer"},
+					},
+				},
+			},
+		},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return toolArguments(response)
+}
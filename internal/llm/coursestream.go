@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// courseStreamParser scans streamed tool-call arguments of the form
+// {..., "lessons": [{...}, ...]} and reports every lesson as soon as its JSON
+// object is complete.
+type courseStreamParser struct {
+	buf      []byte
+	pos      int
+	stack    []byte
+	inString bool
+	escaped  bool
+
+	expectKey   bool
+	key         string
+	stringStart int
+	lessonStart int
+
+	onLesson func(json.RawMessage)
+}
+
+func newCourseStreamParser(onLesson func(json.RawMessage)) *courseStreamParser {
+	return &courseStreamParser{onLesson: onLesson}
+}
+
+func (p *courseStreamParser) Write(chunk string) {
+	p.buf = append(p.buf, chunk...)
+
+	for ; p.pos < len(p.buf); p.pos++ {
+		c := p.buf[p.pos]
+		depth := len(p.stack)
+
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case c == '\\':
+				p.escaped = true
+			case c == '"':
+				p.inString = false
+				if depth == 1 && p.expectKey {
+					p.key, _ = strconv.Unquote(string(p.buf[p.stringStart : p.pos+1]))
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			p.inString = true
+			p.stringStart = p.pos
+		case '{', '[':
+			switch {
+			case depth == 0:
+				p.expectKey = true
+			case depth == 2 && c == '{' && p.key == "lessons":
+				p.lessonStart = p.pos
+			}
+			p.stack = append(p.stack, c)
+		case '}', ']':
+			if depth == 0 {
+				continue
+			}
+			p.stack = p.stack[:depth-1]
+			if depth == 3 && c == '}' && p.key == "lessons" && p.onLesson != nil {
+				p.onLesson(append(json.RawMessage(nil), p.buf[p.lessonStart:p.pos+1]...))
+			}
+		case ':':
+			if depth == 1 {
+				p.expectKey = false
+			}
+		case ',':
+			if depth == 1 {
+				p.expectKey = true
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCourseStreamParser(t *testing.T) {
+	const arguments = `{"course_id": "c", "title": "A \"quoted\" } title", "lessons": [` +
+		`{"lesson_id": "one", "title": "[1] {braces}"},` +
+		`{"lesson_id": "two", "title": "escaped \\\" quote"}` +
+		`], "note": {"lessons": "not a lesson"}}`
+
+	tests := []struct {
+		name  string
+		chunk int // bytes per Write call
+	}{
+		{"whole", len(arguments)},
+		{"byte by byte", 1},
+		{"small chunks", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			p := newCourseStreamParser(func(raw json.RawMessage) {
+				var l OutlineLesson
+				if err := json.Unmarshal(raw, &l); err != nil {
+					t.Fatalf("lesson %s is not valid JSON: %v", raw, err)
+				}
+				ids = append(ids, l.ID)
+			})
+			for i := 0; i < len(arguments); i += tt.chunk {
+				p.Write(arguments[i:min(i+tt.chunk, len(arguments))])
+			}
+
+			if len(ids) != 2 || ids[0] != "one" || ids[1] != "two" {
+				t.Errorf("lessons = %v, want [one two]", ids)
+			}
+		})
+	}
+}
+
+func TestCourseStreamParserWaitsForCompleteLesson(t *testing.T) {
+	calls := 0
+	p := newCourseStreamParser(func(json.RawMessage) { calls++ })
+
+	p.Write(`{"lessons": [{"lesson_id": "one", "title": "Unfinished`)
+	if calls != 0 {
+		t.Fatalf("reported a lesson before it was complete")
+	}
+	p.Write(`"}`)
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
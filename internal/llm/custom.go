@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"strings"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/mozilla-ai/any-llm-go/providers/openai"
+)
+
+// noAPIKey is sent to OpenAI-compatible servers that do not check keys.
+const noAPIKey = "none"
+
+// The custom backend talks to any OpenAI-compatible server (llama.cpp, vLLM, ...).
+func init() {
+	Register(Backend{
+		ID:          "custom",
+		Name:        "Custom (OpenAI-compatible)",
+		New:         newOpenAICompatible,
+		UsesBaseURL: true,
+	})
+}
+
+func newOpenAICompatible(s Settings) (Provider, error) {
+	if err := ValidateBaseURL(s.BaseURL); err != nil {
+		return nil, err
+	}
+	apiKey := s.APIKey
+	if apiKey == "" {
+		apiKey = noAPIKey
+	}
+	return openai.New(
+		anyllm.WithBaseURL(trimBaseURL(s.BaseURL)),
+		anyllm.WithAPIKey(apiKey),
+	)
+}
+
+// trimBaseURL removes surrounding spaces and trailing slashes, so paths can
+// be appended to the base URL.
+func trimBaseURL(baseURL string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/")
+}
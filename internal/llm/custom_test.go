@@ -0,0 +1,43 @@
+package llm
+
+import "testing"
+
+func TestValidateBaseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"http://localhost:8080/v1", false},
+		{"https://example.com/v1", false},
+		{" http://localhost:8080 ", false},
+		{"localhost:8080", true},
+		{"ftp://example.com", true},
+		{"file:///tmp/socket", true},
+		{"http://", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateBaseURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBaseURL(%q) = %v, want error %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTrimBaseURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"http://localhost:8080/v1":     "http://localhost:8080/v1",
+		"http://localhost:8080/v1/":    "http://localhost:8080/v1",
+		" http://localhost:8080/v1// ": "http://localhost:8080/v1",
+	} {
+		if got := trimBaseURL(in); got != want {
+			t.Errorf("trimBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewOpenAICompatibleRejectsInvalidURL(t *testing.T) {
+	if _, err := newOpenAICompatible(Settings{BaseURL: "localhost:8080"}); err == nil {
+		t.Error("newOpenAICompatible accepted a URL without a scheme")
+	}
+}
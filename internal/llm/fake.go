@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/spf13/viper"
+)
+
+// The fake backend replays tool-call arguments from fixture files instead of
+// calling a model, so tests and demos run without API keys.
+//
+// Fixtures are named after the tool: <tool>.json, or <tool>.1.json,
+// <tool>.2.json, ... for a sequence of calls (the last one repeats).
+// Setting record_fixtures (PROGOAT_RECORD) writes fixtures in the same layout
+// from the responses of a real provider.
+func init() {
+	Register(Backend{
+		ID:   "fake",
+		Name: "Fake (replay fixtures)",
+		New: func(s Settings) (Provider, error) {
+			dir := viper.GetString("providers.fake.fixtures")
+			if dir == "" {
+				return nil, fmt.Errorf("fixtures directory is not set (providers.fake.fixtures or PROGOAT_FIXTURES)")
+			}
+			return &replayProvider{dir: dir}, nil
+		},
+		Hidden: true,
+	})
+}
+
+var (
+	fixtureMu    sync.Mutex
+	fixtureCalls = map[string]int{}
+)
+
+// nextFixtureCall returns how many times the tool has been called in dir,
+// counting this call.
+func nextFixtureCall(dir, tool string) int {
+	fixtureMu.Lock()
+	defer fixtureMu.Unlock()
+	key := filepath.Join(dir, tool)
+	fixtureCalls[key]++
+	return fixtureCalls[key]
+}
+
+// replaysInOrder reports whether p numbers fixtures in call order, so calls
+// through it must be made one at a time to stay deterministic.
+func replaysInOrder(p Provider) bool {
+	switch p.(type) {
+	case *replayProvider, *recordingProvider:
+		return true
+	}
+	return false
+}
+
+func fixtureName(tool string, n int) string {
+	return fmt.Sprintf("%s.%d.json", tool, n)
+}
+
+func requestedTool(params anyllm.CompletionParams) (string, error) {
+	if len(params.Tools) == 0 {
+		return "", fmt.Errorf("fake provider can only replay tool calls")
+	}
+	return params.Tools[0].Function.Name, nil
+}
+
+type replayProvider struct {
+	dir string
+}
+
+func (p *replayProvider) Completion(ctx context.Context, params anyllm.CompletionParams) (*anyllm.ChatCompletion, error) {
+	tool, err := requestedTool(params)
+	if err != nil {
+		return nil, err
+	}
+
+	n := nextFixtureCall(p.dir, tool)
+	candidates := []string{}
+	for i := n; i >= 1; i-- {
+		candidates = append(candidates, fixtureName(tool, i))
+	}
+	candidates = append(candidates, tool+".json")
+
+	for _, name := range candidates {
+		arguments, err := os.ReadFile(filepath.Join(p.dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &anyllm.ChatCompletion{
+			Model: params.Model,
+			Choices: []anyllm.Choice{{
+				Message: anyllm.Message{
+					Role: anyllm.RoleAssistant,
+					ToolCalls: []anyllm.ToolCall{{
+						ID:       fmt.Sprintf("fake-%s-%d", tool, n),
+						Type:     "function",
+						Function: anyllm.FunctionCall{Name: tool, Arguments: string(arguments)},
+					}},
+				},
+				FinishReason: "tool_calls",
+			}},
+		}, nil
+	}
+	return nil, fmt.Errorf("no fixture for tool '%s' in %s", tool, p.dir)
+}
+
+// recordingProvider passes calls through and saves the returned tool-call
+// arguments as fixtures for the fake backend.
+type recordingProvider struct {
+	provider Provider
+	dir      string
+}
+
+func (p *recordingProvider) Completion(ctx context.Context, params anyllm.CompletionParams) (*anyllm.ChatCompletion, error) {
+	response, err := p.provider.Completion(ctx, params)
+	if err != nil {
+		return response, err
+	}
+
+	tool, err := requestedTool(params)
+	if err != nil {
+		return response, nil
+	}
+	arguments, err := toolArguments(response)
+	if err != nil {
+		return response, nil
+	}
+
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(arguments), "", "  ") == nil {
+		arguments = indented.String()
+	}
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(p.dir, fixtureName(tool, nextFixtureCall(p.dir, tool)))
+	if err := os.WriteFile(path, []byte(arguments), 0644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,348 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/usage"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/spf13/viper"
+)
+
+// Courses are generated in two phases: an outline with the lesson titles and
+// objectives, then one call per lesson to write its slides and task. This
+// keeps every response well below the output token limits.
+
+// Outline is the plan of a course before its lessons are written.
+type Outline struct {
+	ID                  string          `json:"course_id"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	ProgrammingLanguage string          `json:"programming_language"`
+	Lessons             []OutlineLesson `json:"lessons"`
+}
+
+// course returns the outline as a course without lesson content.
+func (o Outline) course() course.Course {
+	c := course.Course{
+		ID:                  o.ID,
+		Title:               o.Title,
+		Description:         o.Description,
+		ProgrammingLanguage: o.ProgrammingLanguage,
+	}
+	for _, l := range o.Lessons {
+		c.Lessons = append(c.Lessons, course.Lesson{ID: l.ID, Title: l.Title})
+	}
+	return c
+}
+
+// Validate checks the course metadata and lesson IDs of the outline.
+func (o Outline) Validate() error {
+	return o.course().ValidateOutline()
+}
+
+type OutlineLesson struct {
+	ID         string `json:"lesson_id"`
+	Title      string `json:"title"`
+	Objectives string `json:"objectives"`
+}
+
+const defaultGenerationConcurrency = 3
+
+const outlineInstructions = `You are a professional coding instructor. Your task is to plan a structured programming course based on the user's topic.
+Strictly follow these language requirements:
+1. Use the same language as the user's prompt for the following fields: "title", "description" and the "title" and "objectives" of each lesson.
+2. Use English for all other fields, technical identifiers, and metadata to ensure system compatibility.
+3. Course ID should be short and super simple.
+4. Course Title should be simple.
+5. Lesson IDs should be short, unique and usable as directory names.
+6. In the tool call arguments, write "course_id", "title", "description" and "programming_language" before "lessons".`
+
+const lessonInstructions = `You are a professional coding instructor. Your task is to write one lesson of a structured programming course.
+Strictly follow these language requirements:
+1. Use the same language as the user's prompt for the following fields: "task_description", "slides", and any comments within "initial_code".
+2. Use English for all other fields, technical identifiers, and metadata to ensure system compatibility.
+3. In "initial_code", provide the actual source code in the target programming language, but ensure all explanatory comments are in the user's language.
+4. Use markdown for the slides to make them easy to read.
+5. The "initial_code" MUST be an INCOMPLETE boilerplate. It should provide the basic structure (e.g., package declaration, imports, function signatures), but the core logic required to solve the task MUST be left blank or replaced with a TODO comment.
+6. Use "// TODO:" or "/* TODO: */" comments (in the user's language) to clearly indicate where the student needs to write their code.
+7. Ensure the "initial_code" is not a finished solution. The goal is for the student to implement the logic themselves.
+8. Only cover the objectives of the requested lesson; the other lessons are listed for context.`
+
+var outlineTool = anyllm.Tool{
+	Type: "function",
+	Function: anyllm.Function{
+		Name: "generate_course_outline",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"course_id":            map[string]any{"type": "string"},
+				"title":                map[string]any{"type": "string"},
+				"description":          map[string]any{"type": "string"},
+				"programming_language": map[string]any{"type": "string", "description": "The extension of the created code file(e.g., go, py, js),NOT NATURAL LANGUAGE(ja,en...)"},
+				"lessons": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"lesson_id":  map[string]any{"type": "string"},
+							"title":      map[string]any{"type": "string"},
+							"objectives": map[string]any{"type": "string", "description": "What the student learns and builds in this lesson, in one or two sentences."},
+						},
+						"required": []string{"lesson_id", "title", "objectives"},
+					},
+				},
+			},
+			"required": []string{"course_id", "title", "description", "programming_language", "lessons"},
+		},
+	},
+}
+
+var lessonTool = anyllm.Tool{
+	Type: "function",
+	Function: anyllm.Function{
+		Name: "generate_lesson_data",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"slides": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string"},
+					"description": "An array of markdown strings, where each element is a single slide page. " +
+						"Follow these rules: " +
+						"1. Use '##' for headers to define the start of a new slide content. " +
+						"2. Write naturally in the student's language (the language used in the prompt). " +
+						"3. Do not include page numbers in the markdown string itself."},
+				"task_description": map[string]any{"type": "string"},
+				"initial_code":     map[string]any{"type": "string", "description": "The boilerplate code for the student to start with."},
+				"correct_output":   map[string]any{"type": "string", "description": "The expected standard output (stdout) when the task is correctly implemented."},
+				"file_name":        map[string]any{"type": "string", "description": "The name of code file (e.g., main.go, index.html). For Java and Kotlin, name it after the main class (e.g., Main.java)"},
+				"test_cases": map[string]any{
+					"type": "array",
+					"description": "1 to 5 test cases that a correct solution passes. The first one is visible and matches correct_output. " +
+						"Add hidden cases for edge cases. Use stdin and args only if the task reads them. Leave empty for tasks without a deterministic output (e.g., HTML).",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"stdin":           map[string]any{"type": "string", "description": "Text written to the program's standard input."},
+							"args":            map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Command-line arguments."},
+							"expected_output": map[string]any{"type": "string", "description": "The exact expected standard output (stdout)."},
+							"hidden":          map[string]any{"type": "boolean", "description": "Hide the input and expected output from the student."},
+						},
+						"required": []string{"expected_output"},
+					},
+				},
+			},
+			"required": []string{"slides", "task_description", "initial_code", "correct_output", "file_name", "test_cases"},
+		},
+	},
+}
+
+// GenerateOutline plans a course. The response is streamed when the provider
+// supports it, and onLesson is called for each lesson as soon as it arrives.
+func GenerateOutline(ctx context.Context, prompt, length string, onLesson func(OutlineLesson)) (Outline, error) {
+
+	// Set model
+	provider, activeModel, err := activeProvider("gen_model")
+	if err != nil {
+		return Outline{}, err
+	}
+
+	parser := newCourseStreamParser(func(raw json.RawMessage) {
+		var l OutlineLesson
+		if onLesson != nil && json.Unmarshal(raw, &l) == nil {
+			onLesson(l)
+		}
+	})
+
+	// Generate! Only the first attempt is reported; repairs resend everything.
+	onDelta := parser.Write
+	call := func(params anyllm.CompletionParams) (string, error) {
+		arguments, err := streamToolArguments(ctx, provider, params, onDelta)
+		onDelta = func(string) {}
+		return arguments, err
+	}
+
+	var outline Outline
+	_, err = callWithRepair(ctx, anyllm.CompletionParams{
+		Model: activeModel,
+		Messages: []anyllm.Message{
+			{Role: anyllm.RoleSystem, Content: outlineInstructions},
+			{Role: anyllm.RoleUser, Content: fmt.Sprintf("Topic: \"\"\"\n%s\n\"\"\"", prompt)},
+			{Role: anyllm.RoleUser, Content: fmt.Sprintf("Course length: %s", length)},
+		},
+		Tools:      []anyllm.Tool{outlineTool},
+		ToolChoice: "required",
+	}, call, func(arguments string) error {
+		outline = Outline{}
+		if err := json.Unmarshal([]byte(arguments), &outline); err != nil {
+			return fmt.Errorf("failed to parse JSON:%w", err)
+		}
+		return outline.Validate()
+	})
+	if err != nil {
+		return Outline{}, err
+	}
+
+	return outline, nil
+}
+
+// ExpandOutline writes every lesson of the outline, a few at a time, and saves
+// the assembled course under coursesPath. onLesson is called for each lesson
+// when it is done. If a lesson fails or ctx is cancelled, the lessons written
+// so far are saved as a partial course and its title is returned together
+// with the error.
+func ExpandOutline(ctx context.Context, prompt string, outline Outline, coursesPath string, onLesson func(course.Lesson)) (string, error) {
+
+	// Set model
+	provider, activeModel, err := activeProvider("gen_model")
+	if err != nil {
+		return "", err
+	}
+
+	concurrency := viper.GetInt("generation.concurrency")
+	if concurrency < 1 {
+		concurrency = defaultGenerationConcurrency
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lessons := make([]*course.Lesson, len(outline.Lessons))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+
+	expand := func(i int) {
+		lessonCtx := usage.WithLesson(ctx, outline.ID, outline.Lessons[i].ID)
+		lesson, err := expandLesson(lessonCtx, provider, activeModel, prompt, outline, i)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil && ctx.Err() == nil {
+				firstErr = fmt.Errorf("failed to generate lesson '%s': %w", outline.Lessons[i].ID, err)
+				cancel()
+			}
+			return
+		}
+		lessons[i] = &lesson
+		if onLesson != nil {
+			onLesson(lesson)
+		}
+	}
+
+	// フィクスチャは呼び出し順に番号が付くので、再生・記録中はレッスン順に1つずつ生成する
+	if replaysInOrder(provider) {
+		for i := range outline.Lessons {
+			if ctx.Err() != nil {
+				break
+			}
+			expand(i)
+		}
+	} else {
+		for i := range outline.Lessons {
+			wg.Go(func() {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return
+				}
+				expand(i)
+			})
+		}
+		wg.Wait()
+	}
+
+	c := outline.course()
+	c.Lessons = nil
+	for _, l := range lessons {
+		if l != nil {
+			c.Lessons = append(c.Lessons, *l)
+		}
+	}
+
+	if len(c.Lessons) < len(outline.Lessons) {
+		if firstErr == nil {
+			firstErr = parent.Err()
+		}
+		return salvageCourse(c, len(outline.Lessons), coursesPath, firstErr)
+	}
+
+	courseJson, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON:%w", err)
+	}
+	return course.SaveCourse(string(courseJson), coursesPath)
+}
+
+func expandLesson(ctx context.Context, provider Provider, model, prompt string, outline Outline, i int) (course.Lesson, error) {
+	target := outline.Lessons[i]
+
+	var plan strings.Builder
+	fmt.Fprintf(&plan, "Course: %s (%s)\n%s\nLessons:\n", outline.Title, outline.ProgrammingLanguage, outline.Description)
+	for n, l := range outline.Lessons {
+		fmt.Fprintf(&plan, "%d. %s - %s\n", n+1, l.Title, l.Objectives)
+	}
+
+	request := fmt.Sprintf("Write lesson %d: %s\nObjectives: %s", i+1, target.Title, target.Objectives)
+	if i == 0 {
+		request += "\nThis is the first lesson. Its VERY FIRST slide MUST be a 'Local Setup Guide' explaining how to install the environment for the language and how to run the code on a local machine."
+	}
+
+	var lesson course.Lesson
+	_, err := callWithRepair(ctx, anyllm.CompletionParams{
+		Model: model,
+		Messages: []anyllm.Message{
+			{Role: anyllm.RoleSystem, Content: lessonInstructions},
+			{Role: anyllm.RoleUser, Content: fmt.Sprintf("Topic: \"\"\"\n%s\n\"\"\"", prompt)},
+			{Role: anyllm.RoleUser, Content: plan.String()},
+			{Role: anyllm.RoleUser, Content: request},
+		},
+		Tools:      []anyllm.Tool{lessonTool},
+		ToolChoice: "required",
+	}, func(params anyllm.CompletionParams) (string, error) {
+		response, err := complete(ctx, provider, params)
+		if err != nil {
+			return "", err
+		}
+		return toolArguments(response)
+	}, func(arguments string) error {
+		lesson = course.Lesson{}
+		if err := json.Unmarshal([]byte(arguments), &lesson); err != nil {
+			return fmt.Errorf("failed to parse JSON:%w", err)
+		}
+		return lesson.ValidateContent(outline.ProgrammingLanguage)
+	})
+	if err != nil {
+		return course.Lesson{}, err
+	}
+
+	lesson.ID = target.ID
+	lesson.Title = target.Title
+	return lesson, nil
+}
+
+// salvageCourse saves the lessons of an interrupted generation.
+func salvageCourse(partial course.Course, total int, coursesPath string, cause error) (string, error) {
+	if partial.ID == "" || len(partial.Lessons) == 0 {
+		return "", cause
+	}
+
+	partialJson, err := json.Marshal(partial)
+	if err != nil {
+		return "", cause
+	}
+	title, err := course.SaveCourse(string(partialJson), coursesPath)
+	if err != nil {
+		return "", cause
+	}
+	return title, fmt.Errorf("generation interrupted, saved %d of %d lessons: %w", len(partial.Lessons), total, cause)
+}
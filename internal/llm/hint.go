@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+)
+
+// Hint levels, from the lightest to the most revealing. Each hint asked for
+// in a lesson goes one level further.
+const (
+	HintNudge    = 1 // a question or pointer in the right direction
+	HintApproach = 2 // the steps to take, without code
+	HintSnippet  = 3 // a partial snippet that leaves the key part to the student
+
+	MaxHintLevel = HintSnippet
+)
+
+var hintLevels = map[int]string{
+	HintNudge:    "a nudge: one short question or pointer that makes the student notice what to look at. Do not describe the solution and do not write code.",
+	HintApproach: "the approach: the steps to solve the task in plain words, naming the language features to use. Do not write code.",
+	HintSnippet:  "a partial snippet: a short piece of code for the hardest part, with the rest left as a TODO for the student. Never give the complete solution.",
+}
+
+// HintName is a short label of a hint level.
+func HintName(level int) string {
+	switch level {
+	case HintNudge:
+		return "nudge"
+	case HintApproach:
+		return "approach"
+	}
+	return "partial snippet"
+}
+
+// GenerateHint asks for the hint of the given level. attempts describes the
+// student's previous submissions and previousHints are the hints already
+// shown, so the new one does not repeat them.
+func GenerateHint(ctx context.Context, level int, task, code, attempts string, previousHints []string, courseTitle, lessonTitle string) (string, error) {
+	provider, activeModel, err := activeProvider("judge_model")
+	if err != nil {
+		return "", err
+	}
+
+	level = min(max(level, HintNudge), MaxHintLevel)
+	if attempts == "" {
+		attempts = "(none yet)"
+	}
+	previous := "(none)"
+	if len(previousHints) > 0 {
+		previous = strings.Join(previousHints, "\n---\n")
+	}
+
+	response, err := complete(ctx, provider, anyllm.CompletionParams{
+		Model: activeModel,
+		Messages: []anyllm.Message{
+			{
+				Role: anyllm.RoleSystem,
+				Content: fmt.Sprintf(`You are a patient programming instructor helping a student who is stuck. Give hint %d of %d, which is %s
+Base the hint on the student's current code and previous attempts, go one step further than the previous hints without repeating them, and keep it short. Write in the student's language using Markdown.`,
+					level, MaxHintLevel, hintLevels[level]),
+			},
+			{Role: anyllm.RoleUser, Content: "Task:" + task},
+			{Role: anyllm.RoleUser, Content: "Student Code:" + code},
+			{Role: anyllm.RoleUser, Content: "Previous Attempts:" + attempts},
+			{Role: anyllm.RoleUser, Content: "Previous Hints:" + previous},
+			{Role: anyllm.RoleUser, Content: "Course Title:" + courseTitle},
+			{Role: anyllm.RoleUser, Content: "Lesson Title:" + lessonTitle},
+		},
+		Tools: []anyllm.Tool{
+			{
+				Type: "function",
+				Function: anyllm.Function{
+					Name: "give_hint",
+					Parameters: map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"hint": map[string]any{"type": "string", "description": "The hint in Markdown, in the student's language."},
+						},
+						"required": []string{"hint"},
+					},
+				},
+			},
+		},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return toolArguments(response)
+}
@@ -10,168 +10,59 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/huh"
-	"github.com/minotto165/progoat/internal/course"
 	anyllm "github.com/mozilla-ai/any-llm-go"
-	"github.com/mozilla-ai/any-llm-go/providers/anthropic"
-	"github.com/mozilla-ai/any-llm-go/providers/gemini"
-	"github.com/mozilla-ai/any-llm-go/providers/openai"
-	"github.com/mozilla-ai/any-llm-go/providers/zai"
-	"github.com/spf13/viper"
 )
 
-func GenerateCourse(prompt, length, coursesPath string) (string, error) {
-
-	// Set informations
-	activeProvider := viper.GetString("active_provider")
-	activeModel := viper.GetString(fmt.Sprintf("providers.%s.gen_model", activeProvider))
-	activeApiKey := viper.GetString(fmt.Sprintf("providers.%s.api_key", activeProvider))
-
-	// Set model
-	var provider anyllm.Provider
-	var err error
+// Execution is how the student's program ran.
+type Execution struct {
+	Phase       string // "build" or "run"
+	ExitCode    int
+	Stdout      string
+	Stderr      string
+	Limit       string // the resource limit that stopped the program, if any
+	TestResults string // summary of the test cases, if the lesson has any
+}
 
-	switch activeProvider {
-	case "openai":
-		provider, err = openai.New(anyllm.WithAPIKey(activeApiKey))
-	case "gemini":
-		provider, err = gemini.New(anyllm.WithAPIKey(activeApiKey))
-	case "anthropic":
-		provider, err = anthropic.New(anyllm.WithAPIKey(activeApiKey))
-	case "zai":
-		provider, err = zai.New(anyllm.WithAPIKey(activeApiKey))
-	default:
-		return "", fmt.Errorf("Provider '%s' is not supported or not configured. Please run 'progoat config' first.\n", activeProvider)
+func (e Execution) describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Phase: %s\nExit code: %d\n", e.Phase, e.ExitCode)
+	if e.Phase == "build" && e.ExitCode != 0 {
+		b.WriteString("The program failed to compile.\n")
 	}
-
-	if err != nil {
-		return "", fmt.Errorf("Error during initializing model:%s", err)
+	if e.Limit != "" {
+		fmt.Fprintf(&b, "Execution stopped: %s\n", e.Limit)
 	}
-
-	// Generate!
-	ctx := context.Background()
-	response, err := provider.Completion(ctx, anyllm.CompletionParams{
-		Model: activeModel,
-		Messages: []anyllm.Message{
-			{
-				Role: anyllm.RoleSystem,
-				Content: `You are a professional coding instructor. Your task is to generate a structured programming course based on the user's topic. 
-Strictly follow these language requirements:
-1. Use the same language as the user's prompt for the following fields: "description", "task_description", "title", "slides", and any comments within "initial_code".
-2. Use English for all other fields, technical identifiers, and metadata to ensure system compatibility.
-3. In "initial_code", provide the actual source code in the target programming language, but ensure all explanatory comments are in the user's language.
-4. Use markdown for the slides to make them easy to read.
-5. Course ID should be short and super simple.
-6. Course Title should be simple.
-7. The first slide of the first lesson MUST be a "Setup Guide". It should explain how to install the necessary environment for the language and how to run the code on a local machine.
-8. The "initial_code" MUST be an INCOMPLETE boilerplate. It should provide the basic structure (e.g., package declaration, imports, function signatures), but the core logic required to solve the task MUST be left blank or replaced with a TODO comment.
-9. Use "// TODO:" or "/* TODO: */" comments (in the user's language) to clearly indicate where the student needs to write their code.
-10. Ensure the "initial_code" is not a finished solution. The goal is for the student to implement the logic themselves.`,
-			},
-			{
-				Role:    anyllm.RoleUser,
-				Content: fmt.Sprintf("Topic: \"\"\"\n%s\n\"\"\"", prompt),
-			},
-			{
-				Role:    anyllm.RoleUser,
-				Content: fmt.Sprintf("Course length: %s", length),
-			},
-		},
-		Tools: []anyllm.Tool{
-			{
-				Type: "function",
-				Function: anyllm.Function{
-					Name: "generate_course_data",
-					Parameters: map[string]any{
-						"type": "object",
-						"properties": map[string]any{
-							"course_id":            map[string]any{"type": "string"},
-							"title":                map[string]any{"type": "string"},
-							"description":          map[string]any{"type": "string"},
-							"programming_language": map[string]any{"type": "string", "description": "The extension of the created code file(e.g., go, py, js),NOT NATURAL LANGUAGE(ja,en...)"},
-							"lessons": map[string]any{
-								"type": "array",
-								"items": map[string]any{
-									"type": "object",
-									"properties": map[string]any{
-										"lesson_id": map[string]any{"type": "string"},
-										"title":     map[string]any{"type": "string"},
-										"slides": map[string]any{
-											"type":  "array",
-											"items": map[string]any{"type": "string"},
-											"description": "An array of markdown strings, where each element is a single slide page. " +
-												"Follow these rules: " +
-												"1. Use '##' for headers to define the start of a new slide content. " +
-												"2. Write naturally in the student's language (the language used in the prompt). " +
-												"3. Do not include page numbers in the markdown string itself." +
-												"4. The VERY FIRST slide of the FIRST lesson must be a 'Local Setup Guide' for the programming language (e.g., installation, run commands)."},
-										"task_description": map[string]any{"type": "string"},
-										"initial_code":     map[string]any{"type": "string", "description": "The boilerplate code for the student to start with."},
-										"correct_output":   map[string]any{"type": "string", "description": "The expected standard output (stdout) when the task is correctly implemented."},
-										"file_name":        map[string]any{"type": "string", "description": "The name of code file (e.g., main.go, index.html)"},
-									},
-									"required": []string{"lesson_id", "title", "slides", "task_description", "initial_code", "correct_output"},
-								},
-							},
-						},
-						"required": []string{"course_id", "title", "description", "programming_language", "lessons"},
-					},
-				},
-			},
-		},
-		ToolChoice: "required",
-	})
-
-	if err != nil {
-		return "", err
+	if e.Stderr != "" {
+		fmt.Fprintf(&b, "Stderr:\n%s\n", e.Stderr)
 	}
-
-	if len(response.Choices) == 0 || len(response.Choices[0].Message.ToolCalls) == 0 || response.Choices[0].Message.ToolCalls[0].Function.Arguments == "" {
-		return "", fmt.Errorf("LLM returned an invalid or empty response")
+	if e.TestResults != "" {
+		fmt.Fprintf(&b, "Test results:\n%s", e.TestResults)
 	}
-	return course.SaveCourse(response.Choices[0].Message.ToolCalls[0].Function.Arguments, coursesPath)
-
+	return b.String()
 }
 
-func GenerateJudgement(task, code, out, modelOut, courseTitle, lessonTitle string) (string, error) {
-	// Set informations
-	activeProvider := viper.GetString("active_provider")
-	activeModel := viper.GetString(fmt.Sprintf("providers.%s.judge_model", activeProvider))
-	activeApiKey := viper.GetString(fmt.Sprintf("providers.%s.api_key", activeProvider))
+var scoreSchema = map[string]any{"type": "integer", "minimum": 0, "maximum": 100}
 
+func GenerateJudgement(ctx context.Context, task, code string, execution Execution, modelOut, courseTitle, lessonTitle string) (string, error) {
 	// Set model
-	var provider anyllm.Provider
-	var err error
-
-	switch activeProvider {
-	case "openai":
-		provider, err = openai.New(anyllm.WithAPIKey(activeApiKey))
-	case "gemini":
-		provider, err = gemini.New(anyllm.WithAPIKey(activeApiKey))
-	case "anthropic":
-		provider, err = anthropic.New(anyllm.WithAPIKey(activeApiKey))
-	case "zai":
-		provider, err = zai.New(anyllm.WithAPIKey(activeApiKey))
-	default:
-		return "", fmt.Errorf("Provider '%s' is not supported or not configured. Please run 'progoat config' first.\n", activeProvider)
-	}
-
+	provider, activeModel, err := activeProvider("judge_model")
 	if err != nil {
-		return "", fmt.Errorf("failed to initialize model:%w", err)
+		return "", err
 	}
 
 	// Generate!
-	ctx := context.Background()
-	response, err := provider.Completion(ctx, anyllm.CompletionParams{
+	response, err := complete(ctx, provider, anyllm.CompletionParams{
 		Model: activeModel,
 		Messages: []anyllm.Message{
 			{
 				Role:    anyllm.RoleSystem,
-				Content: `You are a programming instructor. Judge strictly by the code syntax. Treat output as secondary. If correct, keep feedback very brief without redundant explanations or mentioning missing output. Provide feedback in the student's language using Markdown.`,
+				Content: `You are a programming instructor. Judge strictly by the code syntax. Treat output as secondary. If correct, keep feedback very brief without redundant explanations or mentioning missing output. If the program failed to compile or exited with an error, it is not correct; explain the actual error from stderr. If execution was stopped by a time limit, the program never finished (often an infinite loop), so it is not correct; point out the likely cause. Score the submission from 0 to 100 on each criterion of the rubric: correctness (it solves the task), style (readable names and layout), idiomatic (idiomatic use of the language) and edge_cases (handling of unusual input). The overall score weighs correctness most; a program that does not solve the task scores below 50 overall. Provide feedback in the student's language using Markdown.`,
 			},
 			{Role: anyllm.RoleUser, Content: "Task:" + task},
 			{Role: anyllm.RoleUser, Content: "Model Output:" + modelOut},
 			{Role: anyllm.RoleUser, Content: "Student Code:" + code},
-			{Role: anyllm.RoleUser, Content: "Student Output:" + out},
+			{Role: anyllm.RoleUser, Content: "Student Output:" + execution.Stdout},
+			{Role: anyllm.RoleUser, Content: "Execution:" + execution.describe()},
 			{Role: anyllm.RoleUser, Content: "Course Title:" + courseTitle},
 			{Role: anyllm.RoleUser, Content: "Lesson Title:" + lessonTitle},
 		},
@@ -183,10 +74,20 @@ func GenerateJudgement(task, code, out, modelOut, courseTitle, lessonTitle strin
 					Parameters: map[string]any{
 						"type": "object",
 						"properties": map[string]any{
-							"is_correct": map[string]any{"type": "boolean"},
-							"advice":     map[string]any{"type": "string", "description": "Super-Short, helpful feedback in the student's language. Use Markdown but don't break a line."},
+							"score": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"correctness": scoreSchema,
+									"style":       scoreSchema,
+									"idiomatic":   scoreSchema,
+									"edge_cases":  scoreSchema,
+									"overall":     scoreSchema,
+								},
+								"required": []string{"correctness", "style", "idiomatic", "edge_cases", "overall"},
+							},
+							"advice": map[string]any{"type": "string", "description": "Super-Short, helpful feedback in the student's language. Use Markdown but don't break a line."},
 						},
-						"required": []string{"is_correct", "advice"},
+						"required": []string{"score", "advice"},
 					},
 				},
 			},
@@ -198,10 +99,7 @@ func GenerateJudgement(task, code, out, modelOut, courseTitle, lessonTitle strin
 		return "", err
 	}
 
-	if len(response.Choices) == 0 || len(response.Choices[0].Message.ToolCalls) == 0 || response.Choices[0].Message.ToolCalls[0].Function.Arguments == "" {
-		return "", fmt.Errorf("LLM returned an invalid or empty response")
-	}
-	return response.Choices[0].Message.ToolCalls[0].Function.Arguments, nil
+	return toolArguments(response)
 }
 
 //---------------------------
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const ollamaDefaultBaseURL = "http://localhost:11434"
+
+// Ollama is reached through its OpenAI-compatible endpoint (/v1), and the
+// installed models are listed from its native API (/api/tags).
+func init() {
+	Register(Backend{
+		ID:   "ollama",
+		Name: "Ollama",
+		New: func(s Settings) (Provider, error) {
+			s.BaseURL = ollamaBaseURL(s) + "/v1"
+			return newOpenAICompatible(s)
+		},
+		FetchModels: func(ctx context.Context, s Settings) ([]huh.Option[string], error) {
+			return FetchOllamaModels(ctx, ollamaBaseURL(s))
+		},
+		UsesBaseURL:    true,
+		DefaultBaseURL: ollamaDefaultBaseURL,
+	})
+}
+
+func ollamaBaseURL(s Settings) string {
+	baseURL := trimBaseURL(s.BaseURL)
+	if baseURL == "" {
+		return ollamaDefaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/v1")
+}
+
+type ollamaModel struct {
+	Name string `json:"name"` // e.g. "llama3.2:latest"
+	Size int64  `json:"size"`
+}
+
+type ollamaTagsResponse struct {
+	Models []ollamaModel `json:"models"`
+}
+
+// FetchOllamaModels lists the models installed on the Ollama server at baseURL.
+func FetchOllamaModels(ctx context.Context, baseURL string) ([]huh.Option[string], error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama returned HTTP %d", resp.StatusCode)
+	}
+
+	var result ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Models, func(i, j int) bool {
+		return result.Models[i].Name < result.Models[j].Name
+	})
+
+	var options []huh.Option[string]
+	for _, m := range result.Models {
+		label := fmt.Sprintf("%s (%.1f GB)", m.Name, float64(m.Size)/1e9)
+		options = append(options, huh.NewOption(label, m.Name))
+	}
+
+	if len(options) == 0 {
+		return nil, fmt.Errorf("no models installed (run 'ollama pull <model>' first)")
+	}
+	return options, nil
+}
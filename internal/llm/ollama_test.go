@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchOllamaModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"qwen2.5:7b","size":4700000000},{"name":"llama3.2:latest","size":2000000000}]}`))
+	}))
+	defer server.Close()
+
+	options, err := FetchOllamaModels(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("FetchOllamaModels: %v", err)
+	}
+	var values, labels []string
+	for _, o := range options {
+		values = append(values, o.Value)
+		labels = append(labels, o.Key)
+	}
+	if got := strings.Join(values, ","); got != "llama3.2:latest,qwen2.5:7b" {
+		t.Errorf("models = %s, want sorted by name", got)
+	}
+	if labels[0] != "llama3.2:latest (2.0 GB)" {
+		t.Errorf("label = %q", labels[0])
+	}
+}
+
+func TestFetchOllamaModelsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"no models", http.StatusOK, `{"models":[]}`, "no models installed"},
+		{"server error", http.StatusInternalServerError, `{}`, "HTTP 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := FetchOllamaModels(context.Background(), server.URL)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FetchOllamaModels() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOllamaBaseURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                         ollamaDefaultBaseURL,
+		"  ":                       ollamaDefaultBaseURL,
+		"http://gpu-box:11434":     "http://gpu-box:11434",
+		"http://gpu-box:11434/":    "http://gpu-box:11434",
+		"http://gpu-box:11434/v1":  "http://gpu-box:11434",
+		"http://gpu-box:11434/v1/": "http://gpu-box:11434",
+	} {
+		if got := ollamaBaseURL(Settings{BaseURL: in}); got != want {
+			t.Errorf("ollamaBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/spf13/viper"
+)
+
+// Provider is the part of anyllm.Provider that progoat uses.
+type Provider interface {
+	Completion(ctx context.Context, params anyllm.CompletionParams) (*anyllm.ChatCompletion, error)
+}
+
+// Settings holds the values stored under providers.<id> in config.yaml.
+type Settings struct {
+	APIKey  string
+	BaseURL string
+}
+
+// Backend describes a provider that can be selected with `progoat config`.
+// A backend without model options asks for a free-text model name, one
+// without ValidateKey treats the API key as optional, and a hidden one can
+// only be selected by editing config.yaml or setting PROGOAT_PROVIDER.
+type Backend struct {
+	ID             string
+	Name           string
+	New            func(s Settings) (Provider, error)
+	DefaultModels  []huh.Option[string]
+	FetchModels    func(ctx context.Context, s Settings) ([]huh.Option[string], error) // optional
+	ValidateKey    func(apiKey string) error                                           // optional
+	UsesBaseURL    bool
+	DefaultBaseURL string
+	Hidden         bool
+}
+
+var backends []Backend
+
+// Register adds a backend. Backends are listed in registration order.
+func Register(b Backend) {
+	for i, existing := range backends {
+		if existing.ID == b.ID {
+			backends[i] = b
+			return
+		}
+	}
+	backends = append(backends, b)
+}
+
+// Backends returns every registered backend.
+func Backends() []Backend {
+	return append([]Backend(nil), backends...)
+}
+
+// LookupBackend returns the backend registered under id.
+func LookupBackend(id string) (Backend, error) {
+	for _, b := range backends {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return Backend{}, fmt.Errorf("Provider '%s' is not supported or not configured. Please run 'progoat config' first.\n", id)
+}
+
+// LoadSettings reads the settings of a provider from config.
+func LoadSettings(id string) Settings {
+	return Settings{
+		APIKey:  viper.GetString(fmt.Sprintf("providers.%s.api_key", id)),
+		BaseURL: viper.GetString(fmt.Sprintf("providers.%s.base_url", id)),
+	}
+}
+
+// Models returns the model options for a backend, fetching them when the
+// backend supports it. The returned bool reports whether the list was fetched.
+func (b Backend) Models(ctx context.Context, s Settings) ([]huh.Option[string], bool, error) {
+	if b.FetchModels == nil {
+		return b.DefaultModels, false, nil
+	}
+	fetched, err := b.FetchModels(ctx, s)
+	if err != nil {
+		return b.DefaultModels, false, err
+	}
+	return fetched, true, nil
+}
+
+// activeProvider builds the configured provider and returns it with the model
+// stored under modelKey ("gen_model" or "judge_model").
+func activeProvider(modelKey string) (Provider, string, error) {
+	id := viper.GetString("active_provider")
+	b, err := LookupBackend(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	provider, err := b.New(LoadSettings(id))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize model:%w", err)
+	}
+
+	if dir := viper.GetString("record_fixtures"); dir != "" {
+		provider = &recordingProvider{provider: provider, dir: dir}
+	}
+
+	return provider, viper.GetString(fmt.Sprintf("providers.%s.%s", id, modelKey)), nil
+}
+
+// toolArguments returns the arguments of the first tool call in the response.
+func toolArguments(response *anyllm.ChatCompletion) (string, error) {
+	if response == nil || len(response.Choices) == 0 || len(response.Choices[0].Message.ToolCalls) == 0 || response.Choices[0].Message.ToolCalls[0].Function.Arguments == "" {
+		return "", fmt.Errorf("LLM returned an invalid or empty response")
+	}
+	return response.Choices[0].Message.ToolCalls[0].Function.Arguments, nil
+}
+
+// ValidateBaseURL checks that rawURL is an absolute http(s) URL.
+func ValidateBaseURL(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base URL must start with http:// or https://")
+	}
+	return nil
+}
+
+func requireKey(apiKey string) error {
+	if strings.TrimSpace(apiKey) == "" {
+		return fmt.Errorf("API key is required")
+	}
+	return nil
+}
+
+func requireKeyPrefix(prefix string) func(string) error {
+	return func(apiKey string) error {
+		if err := requireKey(apiKey); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(apiKey, prefix) {
+			return fmt.Errorf("API key should start with %q", prefix)
+		}
+		return nil
+	}
+}
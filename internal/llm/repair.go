@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/spf13/viper"
+)
+
+const defaultMaxRepairs = 2
+
+// callWithRepair runs call and checks the returned tool-call arguments with
+// validate. When they are invalid, the errors are sent back to the model and
+// the call is retried, up to generation.max_repairs times.
+func callWithRepair(ctx context.Context, params anyllm.CompletionParams, call func(anyllm.CompletionParams) (string, error), validate func(arguments string) error) (string, error) {
+	maxRepairs := defaultMaxRepairs
+	if viper.IsSet("generation.max_repairs") {
+		maxRepairs = max(viper.GetInt("generation.max_repairs"), 0)
+	}
+
+	for attempt := 0; ; attempt++ {
+		arguments, err := call(params)
+		if err != nil {
+			return "", err
+		}
+
+		invalid := validate(arguments)
+		if invalid == nil {
+			return arguments, nil
+		}
+		if attempt >= maxRepairs {
+			return "", fmt.Errorf("LLM response is still invalid after %d repair attempts:\n%w", attempt, invalid)
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		params.Messages = append(slices.Clone(params.Messages), anyllm.Message{
+			Role: anyllm.RoleUser,
+			Content: fmt.Sprintf("Your previous tool call arguments were:\n```json\n%s\n```\n"+
+				"They have the following problems:\n%s\n"+
+				"Call the tool again with all of the arguments, fixing every problem.", arguments, invalid),
+		})
+	}
+}
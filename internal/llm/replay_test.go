@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/spf13/viper"
+)
+
+// useFixtures selects the fake provider replaying docs/fixtures and restarts
+// the fixture sequences.
+func useFixtures(t *testing.T) {
+	t.Helper()
+	viper.Set("active_provider", "fake")
+	viper.Set("providers.fake.fixtures", filepath.Join("..", "..", "docs", "fixtures"))
+	t.Cleanup(viper.Reset)
+
+	fixtureMu.Lock()
+	fixtureCalls = map[string]int{}
+	fixtureMu.Unlock()
+}
+
+func TestGenerateOutlineReplaysFixture(t *testing.T) {
+	useFixtures(t)
+
+	var streamed []string
+	outline, err := GenerateOutline(context.Background(), "Go concurrency", "short", func(l OutlineLesson) {
+		streamed = append(streamed, l.ID)
+	})
+	if err != nil {
+		t.Fatalf("GenerateOutline: %v", err)
+	}
+
+	if outline.ID != "go-concurrency" || outline.ProgrammingLanguage != "go" {
+		t.Errorf("outline = %q (%q), want go-concurrency (go)", outline.ID, outline.ProgrammingLanguage)
+	}
+	want := []string{"goroutines", "channels"}
+	if strings.Join(streamed, ",") != strings.Join(want, ",") {
+		t.Errorf("streamed lessons = %v, want %v", streamed, want)
+	}
+}
+
+func TestExpandOutlineKeepsFixturesInLessonOrder(t *testing.T) {
+	// 並列生成の既定値のままでも、フィクスチャはレッスン順に割り当てられること
+	for run := range 3 {
+		useFixtures(t)
+		viper.Set("generation.concurrency", 3)
+
+		outline, err := GenerateOutline(context.Background(), "Go concurrency", "short", nil)
+		if err != nil {
+			t.Fatalf("GenerateOutline: %v", err)
+		}
+
+		coursesPath := t.TempDir()
+		title, err := ExpandOutline(context.Background(), "Go concurrency", outline, coursesPath, nil)
+		if err != nil {
+			t.Fatalf("run %d: ExpandOutline: %v", run, err)
+		}
+		if title != "Go Concurrency Basics" {
+			t.Errorf("run %d: title = %q", run, title)
+		}
+
+		c, err := course.GetCourseStruct("go-concurrency", coursesPath)
+		if err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if len(c.Lessons) != 2 || c.Lessons[0].ID != "goroutines" || c.Lessons[1].ID != "channels" {
+			t.Fatalf("run %d: lessons = %+v", run, c.Lessons)
+		}
+		if !strings.Contains(c.Lessons[0].Slides[0], "Setup Guide") {
+			t.Errorf("run %d: the first lesson did not get generate_lesson_data.1.json", run)
+		}
+		if _, err := os.Stat(course.LessonFile(filepath.Join(coursesPath, c.ID), c.Lessons[0])); err != nil {
+			t.Errorf("run %d: lesson file not written: %v", run, err)
+		}
+	}
+}
+
+func TestGenerateJudgementReplaysScore(t *testing.T) {
+	useFixtures(t)
+
+	response, err := GenerateJudgement(context.Background(), "Print 55.", "package main", Execution{Phase: "run", Stdout: "55\n"}, "55", "Go Concurrency Basics", "Goroutines")
+	if err != nil {
+		t.Fatalf("GenerateJudgement: %v", err)
+	}
+
+	var judgement struct {
+		Score struct {
+			Correctness int `json:"correctness"`
+			Overall     int `json:"overall"`
+		} `json:"score"`
+		Advice string `json:"advice"`
+	}
+	if err := json.Unmarshal([]byte(response), &judgement); err != nil {
+		t.Fatalf("unmarshal %q: %v", response, err)
+	}
+	if judgement.Score.Overall != 90 || judgement.Score.Correctness != 100 || judgement.Advice == "" {
+		t.Errorf("judgement = %+v", judgement)
+	}
+}
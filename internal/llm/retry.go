@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/openai/openai-go"
+	"github.com/spf13/viper"
+	"google.golang.org/genai"
+)
+
+// Retry policy for LLM calls, configurable under llm.* in config.yaml.
+const (
+	defaultCallTimeout   = 3 * time.Minute
+	defaultMaxRetries    = 3
+	defaultRetryDelay    = time.Second
+	defaultMaxRetryDelay = 30 * time.Second
+)
+
+type retryPolicy struct {
+	timeout       time.Duration
+	maxRetries    int
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+}
+
+func loadRetryPolicy() retryPolicy {
+	p := retryPolicy{
+		timeout:       viper.GetDuration("llm.timeout"),
+		maxRetries:    defaultMaxRetries,
+		retryDelay:    viper.GetDuration("llm.retry_delay"),
+		maxRetryDelay: viper.GetDuration("llm.max_retry_delay"),
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultCallTimeout
+	}
+	if viper.IsSet("llm.max_retries") {
+		p.maxRetries = max(viper.GetInt("llm.max_retries"), 0)
+	}
+	if p.retryDelay <= 0 {
+		p.retryDelay = defaultRetryDelay
+	}
+	if p.maxRetryDelay <= 0 {
+		p.maxRetryDelay = defaultMaxRetryDelay
+	}
+	return p
+}
+
+// backoff returns the wait before the given retry (0-based): exponential with
+// full jitter, capped at maxRetryDelay.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.maxRetryDelay
+	if attempt < 30 {
+		delay = min(p.retryDelay<<attempt, p.maxRetryDelay)
+	}
+	return time.Duration(rand.Int64N(int64(delay)) + 1)
+}
+
+// noRetryError marks an error that must not be retried.
+type noRetryError struct {
+	error
+}
+
+func (e noRetryError) Unwrap() error {
+	return e.error
+}
+
+// withRetry runs call with the per-call timeout and retries transient
+// failures. It stops as soon as ctx is cancelled.
+func withRetry(ctx context.Context, call func(ctx context.Context) error) error {
+	policy := loadRetryPolicy()
+
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, policy.timeout)
+		err := call(callCtx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var permanent noRetryError
+		if errors.As(err, &permanent) {
+			return permanent.error
+		}
+		if attempt >= policy.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// retryableStatus are the HTTP statuses worth retrying: request timeout,
+// rate limiting and server-side errors (529 is Anthropic's "overloaded").
+var retryableStatus = []int{408, 429, 500, 502, 503, 504, 529}
+
+// isRetryable reports whether err is transient: a timeout, a dropped
+// connection, rate limiting or a server-side error. Errors are classified by
+// type, never by their message.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, anyllm.ErrRateLimit) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return slices.Contains(retryableStatus, statusCode(err))
+}
+
+// statusCode returns the HTTP status of the provider API error wrapped in
+// err, or 0 when there is none. any-llm-go keeps the SDK error as the cause.
+func statusCode(err error) int {
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.StatusCode
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return anthropicErr.StatusCode
+	}
+	// genai は値で返すが、ポインタで包まれることもある
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		return geminiErr.Code
+	}
+	var geminiPtr *genai.APIError
+	if errors.As(err, &geminiPtr) {
+		return geminiPtr.Code
+	}
+	var providerErr *anyllm.ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode
+	}
+	return 0
+}
+
+// complete is provider.Completion with the retry policy applied.
+func complete(ctx context.Context, provider Provider, params anyllm.CompletionParams) (*anyllm.ChatCompletion, error) {
+	var response *anyllm.ChatCompletion
+	err := withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = provider.Completion(ctx, params)
+		return err
+	})
+	if err == nil && response != nil {
+		recordUsage(ctx, params.Model, response.Usage)
+	}
+	return response, err
+}
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anyllmerrors "github.com/mozilla-ai/any-llm-go/errors"
+	"github.com/openai/openai-go"
+	"github.com/spf13/viper"
+	"google.golang.org/genai"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"openai 429", &openai.Error{StatusCode: 429}, true},
+		{"openai 503", fmt.Errorf("completion: %w", &openai.Error{StatusCode: 503}), true},
+		{"openai 400", &openai.Error{StatusCode: 400}, false},
+		{"anthropic overloaded", &anthropic.Error{StatusCode: 529}, true},
+		{"anthropic 401", &anthropic.Error{StatusCode: 401}, false},
+		{"gemini 500", genai.APIError{Code: 500}, true},
+		{"gemini 404", &genai.APIError{Code: 404}, false},
+		{"any-llm rate limit", anyllmerrors.NewRateLimitError("openai", errors.New("slow down")), true},
+		{"any-llm wraps the SDK error", anyllmerrors.NewProviderError("openai", &openai.Error{StatusCode: 502}), true},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), true},
+		{"network timeout", &net.DNSError{IsTimeout: true}, true},
+		{"canceled", context.Canceled, false},
+		{"status-like message", errors.New("read 500 bytes: rate limit"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: isRetryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fastRetries makes withRetry wait at most a few milliseconds.
+func fastRetries(t *testing.T) {
+	viper.Set("llm.max_retries", 3)
+	viper.Set("llm.retry_delay", "1ms")
+	viper.Set("llm.max_retry_delay", "2ms")
+	t.Cleanup(viper.Reset)
+}
+
+func TestWithRetry(t *testing.T) {
+	fastRetries(t)
+	unavailable := &openai.Error{StatusCode: 503}
+	badRequest := &openai.Error{StatusCode: 400}
+
+	tests := []struct {
+		name      string
+		errs      []error // returned by successive calls; nil after the last
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", nil, 1, nil},
+		{"recovers", []error{unavailable, unavailable}, 3, nil},
+		{"gives up", []error{unavailable, unavailable, unavailable, unavailable, unavailable}, 4, unavailable},
+		{"bad request", []error{badRequest}, 1, badRequest},
+		{"no retry", []error{noRetryError{unavailable}}, 1, unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withRetry(context.Background(), func(context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			// SDK のエラーは Request が nil だと Error() で落ちるので、値を比べるだけにする
+			if err != tt.wantErr {
+				t.Errorf("err = %T, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenCancelled(t *testing.T) {
+	viper.Set("llm.retry_delay", "1h")
+	viper.Set("llm.max_retry_delay", "1h")
+	t.Cleanup(viper.Reset)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := withRetry(ctx, func(context.Context) error { return &openai.Error{StatusCode: 503} })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %T, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("withRetry returned after %s", elapsed)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	p := retryPolicy{retryDelay: time.Second, maxRetryDelay: 5 * time.Second}
+	for attempt := range 100 {
+		limit := p.maxRetryDelay
+		if attempt < 2 {
+			limit = p.retryDelay << attempt
+		}
+		for range 20 {
+			if d := p.backoff(attempt); d <= 0 || d > limit {
+				t.Fatalf("backoff(%d) = %s, want (0, %s]", attempt, d, limit)
+			}
+		}
+	}
+}
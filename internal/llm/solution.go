@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/minotto165/progoat/internal/course"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+)
+
+// GenerateSolution writes a reference solution of the lesson's task,
+// starting from its initial code.
+func GenerateSolution(ctx context.Context, lesson course.Lesson, language, courseTitle string) (string, error) {
+	provider, activeModel, err := activeProvider("gen_model")
+	if err != nil {
+		return "", err
+	}
+
+	var cases strings.Builder
+	for i, tc := range lesson.Cases() {
+		fmt.Fprintf(&cases, "Case %d:", i+1)
+		if len(tc.Args) > 0 {
+			fmt.Fprintf(&cases, " args %q", tc.Args)
+		}
+		if tc.Stdin != "" {
+			fmt.Fprintf(&cases, " stdin %q", tc.Stdin)
+		}
+		fmt.Fprintf(&cases, " expected output %q\n", tc.ExpectedOutput)
+	}
+	if cases.Len() == 0 {
+		cases.WriteString("(none)")
+	}
+
+	response, err := complete(ctx, provider, anyllm.CompletionParams{
+		Model: activeModel,
+		Messages: []anyllm.Message{
+			{
+				Role:    anyllm.RoleSystem,
+				Content: `You are a professional coding instructor. Write the reference solution of a lesson's task for a student who gave up. Complete the initial code so that it is a whole, runnable file that produces exactly the expected output of every test case. Keep the structure and comments of the initial code, use only what the lesson teaches, and write idiomatic, simple code. Explain the key idea briefly in the same language as the task description, using Markdown.`,
+			},
+			{Role: anyllm.RoleUser, Content: "Programming Language:" + language},
+			{Role: anyllm.RoleUser, Content: "File Name:" + lesson.FileName},
+			{Role: anyllm.RoleUser, Content: "Task:" + lesson.TaskDescription},
+			{Role: anyllm.RoleUser, Content: "Initial Code:" + lesson.InitialCode},
+			{Role: anyllm.RoleUser, Content: "Test Cases:" + cases.String()},
+			{Role: anyllm.RoleUser, Content: "Course Title:" + courseTitle},
+			{Role: anyllm.RoleUser, Content: "Lesson Title:" + lesson.Title},
+		},
+		Tools: []anyllm.Tool{
+			{
+				Type: "function",
+				Function: anyllm.Function{
+					Name: "write_solution",
+					Parameters: map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"code":        map[string]any{"type": "string", "description": "The complete source file."},
+							"explanation": map[string]any{"type": "string", "description": "A short explanation of the solution in Markdown."},
+						},
+						"required": []string{"code", "explanation"},
+					},
+				},
+			},
+		},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return toolArguments(response)
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	anyllm "github.com/mozilla-ai/any-llm-go"
+)
+
+// streamingProvider is implemented by providers that can stream completions.
+type streamingProvider interface {
+	CompletionStream(ctx context.Context, params anyllm.CompletionParams) (<-chan anyllm.ChatCompletionChunk, <-chan error)
+}
+
+// streamToolArguments runs the completion and passes the tool-call arguments
+// to onDelta as they arrive. Providers that cannot stream deliver everything in
+// a single delta. Failures are retried as long as nothing has been delivered;
+// after that, the arguments received so far are returned with the error.
+func streamToolArguments(ctx context.Context, provider Provider, params anyllm.CompletionParams, onDelta func(string)) (string, error) {
+	sp, ok := provider.(streamingProvider)
+	if !ok {
+		response, err := complete(ctx, provider, params)
+		if err != nil {
+			return "", err
+		}
+		arguments, err := toolArguments(response)
+		if err != nil {
+			return "", err
+		}
+		onDelta(arguments)
+		return arguments, nil
+	}
+
+	var arguments strings.Builder
+	err := withRetry(ctx, func(ctx context.Context) error {
+		chunks, errs := sp.CompletionStream(ctx, params)
+
+		for chunk := range chunks {
+			if chunk.Usage != nil {
+				recordUsage(ctx, params.Model, chunk.Usage)
+			}
+			for _, choice := range chunk.Choices {
+				for _, toolCall := range choice.Delta.ToolCalls {
+					if toolCall.Function.Arguments == "" {
+						continue
+					}
+					arguments.WriteString(toolCall.Function.Arguments)
+					onDelta(toolCall.Function.Arguments)
+				}
+			}
+		}
+
+		err := <-errs
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil && arguments.Len() > 0 {
+			return noRetryError{err}
+		}
+		return err
+	})
+
+	if err != nil {
+		return arguments.String(), err
+	}
+	if arguments.Len() == 0 {
+		return "", fmt.Errorf("LLM returned an invalid or empty response")
+	}
+	return arguments.String(), nil
+}
@@ -0,0 +1,26 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/minotto165/progoat/internal/usage"
+	anyllm "github.com/mozilla-ai/any-llm-go"
+	"github.com/spf13/viper"
+)
+
+// recordUsage adds the token usage of a call to the recorder of ctx, if any.
+func recordUsage(ctx context.Context, model string, u *anyllm.Usage) {
+	if u == nil {
+		return
+	}
+	courseID, lessonID := usage.LessonFromContext(ctx)
+	usage.FromContext(ctx).Add(usage.Entry{
+		Provider:         viper.GetString("active_provider"),
+		Model:            model,
+		CourseID:         courseID,
+		LessonID:         lessonID,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	})
+}
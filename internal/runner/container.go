@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// containerDir is where the lesson directory is mounted in the container.
+const containerDir = "/lesson"
+
+// defaultImages are keyed by file extension and can be replaced under
+// runner.container.images.<extension>.
+var defaultImages = map[string]string{
+	"go":   "golang:1.25",
+	"py":   "python:3.13-slim",
+	"js":   "node:22-slim",
+	"rb":   "ruby:3.4-slim",
+	"php":  "php:8.4-cli",
+	"sh":   "bash:5",
+	"c":    "gcc:14",
+	"cpp":  "gcc:14",
+	"rs":   "rust:1-slim",
+	"java": "eclipse-temurin:21",
+}
+
+// Container runs programs in a local Docker or Podman image with the lesson
+// directory mounted at /lesson, no network and a read-only root filesystem.
+// Images are never pulled, so they must be available locally.
+type Container struct {
+	Engine string // docker or podman
+	Image  string
+	Limits Limits
+}
+
+var containerSeq atomic.Int64
+
+// NewContainer returns a container runner for programs written in language.
+func NewContainer(language string, limits Limits) (*Container, error) {
+	engine := viper.GetString("runner.container.engine")
+	if engine == "" {
+		for _, candidate := range []string{"docker", "podman"} {
+			if _, err := exec.LookPath(candidate); err == nil {
+				engine = candidate
+				break
+			}
+		}
+		if engine == "" {
+			return nil, errors.New("the container runner needs docker or podman in PATH")
+		}
+	}
+
+	image := viper.GetString("runner.container.images." + language)
+	if image == "" {
+		image = defaultImages[language]
+	}
+	if image == "" {
+		return nil, fmt.Errorf("no container image for .%s files; set runner.container.images.%s in config.yaml", language, language)
+	}
+
+	return &Container{Engine: engine, Image: image, Limits: limits}, nil
+}
+
+func (c *Container) Run(ctx context.Context, cmd Command) (Result, error) {
+	if len(cmd.Args) == 0 {
+		return Result{}, errors.New("no command to run")
+	}
+
+	runCtx := ctx
+	if c.Limits.WallTime > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, c.Limits.WallTime)
+		defer cancel()
+	}
+
+	name := fmt.Sprintf("progoat-%d-%d", os.Getpid(), containerSeq.Add(1))
+	args := c.runArgs(name, cmd.Dir)
+	for _, arg := range cmd.Args {
+		args = append(args, hostToContainer(arg, cmd.Dir))
+	}
+
+	// エンジンの CLI を止めてもコンテナは止まらないので、名前で kill する
+	proc := exec.CommandContext(runCtx, c.Engine, args...)
+	proc.Stdin = strings.NewReader(cmd.Stdin)
+	output := newCapture(proc, c.Limits.Output)
+	proc.Cancel = func() error {
+		c.kill(name)
+		return proc.Process.Kill()
+	}
+	proc.WaitDelay = 5 * time.Second
+
+	err := proc.Run()
+	if output.truncated() {
+		c.kill(name)
+	}
+	if proc.ProcessState == nil {
+		return Result{}, err
+	}
+	res, err := output.result(err)
+	if err != nil {
+		return res, err
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		res.Limit = c.Limits.timeExceeded()
+	case output.truncated():
+		res.Limit = c.Limits.outputExceeded()
+	case c.isEngineError(res):
+		// コンテナを起動できなかった (イメージがない、など)
+		return Result{}, fmt.Errorf("%s could not run %s (pull it first with `%s pull %s`): %s",
+			c.Engine, c.Image, c.Engine, c.Image, strings.TrimSpace(res.Stderr))
+	case res.ExitCode == 128+24: // SIGXCPU
+		res.Limit = fmt.Sprintf("CPU time limit exceeded (%s)", c.Limits.CPUTime)
+	case res.ExitCode == 128+25: // SIGXFSZ
+		res.Limit = fmt.Sprintf("file size limit exceeded (%d MB)", c.Limits.FileSize>>20)
+	case res.ExitCode == 128+9 && c.Limits.Memory > 0: // SIGKILL, usually the OOM killer
+		res.Limit = fmt.Sprintf("memory limit exceeded (%d MB)", c.Limits.Memory>>20)
+	}
+	return res, nil
+}
+
+// isEngineError reports whether the engine itself failed. Both engines exit
+// with 125 then, which a program may also do, so their error message is
+// checked as well.
+func (c *Container) isEngineError(res Result) bool {
+	if res.ExitCode != 125 {
+		return false
+	}
+	for _, line := range strings.Split(res.Stderr, "\n") {
+		// docker: Error response from daemon: ... / Error: ...: image not known (podman)
+		if strings.HasPrefix(line, filepath.Base(c.Engine)+": ") || strings.HasPrefix(line, "Error: ") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Container) runArgs(name, dir string) []string {
+	args := []string{
+		"run", "--rm", "-i",
+		"--name", name,
+		"--pull", "never",
+		// プログラムを PID 1 にすると SIGXCPU などが届かない
+		"--init",
+		"--network", "none",
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,size=512m",
+		"--pids-limit", "256",
+		"-v", dir + ":" + containerDir,
+		"-w", containerDir,
+		"-e", "HOME=/tmp",
+		"-e", "XDG_CACHE_HOME=/tmp/.cache",
+		"-e", "GOCACHE=/tmp/go-build",
+	}
+
+	// レッスンディレクトリに作られるファイルを利用者の所有にする
+	if c.Engine == "podman" {
+		args = append(args, "--userns", "keep-id")
+	} else if uid := os.Getuid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+
+	if c.Limits.Memory > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%dm", c.Limits.Memory>>20))
+	}
+	if c.Limits.CPUTime > 0 {
+		seconds := int64((c.Limits.CPUTime + time.Second - 1) / time.Second)
+		args = append(args, "--ulimit", fmt.Sprintf("cpu=%d:%d", seconds, seconds+1))
+	}
+	if c.Limits.FileSize > 0 {
+		args = append(args, "--ulimit", fmt.Sprintf("fsize=%d:%d", c.Limits.FileSize, c.Limits.FileSize))
+	}
+	return append(args, c.Image)
+}
+
+// kill stops the container; it is removed by --rm.
+func (c *Container) kill(name string) {
+	exec.Command(c.Engine, "kill", name).Run()
+}
+
+// hostToContainer rewrites a path inside the lesson directory to its path in
+// the container. Other arguments are returned unchanged.
+func hostToContainer(arg, dir string) string {
+	rel, err := filepath.Rel(dir, arg)
+	if err != nil || !filepath.IsAbs(arg) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return arg
+	}
+	return path.Join(containerDir, filepath.ToSlash(rel))
+}
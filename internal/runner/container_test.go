@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestContainerIsEngineError(t *testing.T) {
+	tests := []struct {
+		engine string
+		res    Result
+		want   bool
+	}{
+		{"docker", Result{ExitCode: 125, Stderr: "docker: Error response from daemon: No such image: golang:1.25.\n\nRun 'docker run --help' for more information\n"}, true},
+		{"/usr/bin/docker", Result{ExitCode: 125, Stderr: "docker: invalid reference format.\n"}, true},
+		{"podman", Result{ExitCode: 125, Stderr: "Error: golang:1.25: image not known\n"}, true},
+		{"docker", Result{ExitCode: 125, Stderr: "bye\n"}, false},
+		{"docker", Result{ExitCode: 125}, false},
+		{"docker", Result{ExitCode: 1, Stderr: "docker: Error response from daemon\n"}, false},
+	}
+
+	for _, tt := range tests {
+		c := &Container{Engine: tt.engine}
+		if got := c.isEngineError(tt.res); got != tt.want {
+			t.Errorf("isEngineError(%s, %+v) = %v, want %v", tt.engine, tt.res, got, tt.want)
+		}
+	}
+}
+
+func TestHostToContainer(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		arg, want string
+	}{
+		{filepath.Join(dir, "main.go"), "/lesson/main.go"},
+		{filepath.Join(dir, "bin", "main"), "/lesson/bin/main"},
+		{dir, "/lesson"},
+		{filepath.Join(dir, "..", "other", "main.go"), filepath.Join(dir, "..", "other", "main.go")},
+		{filepath.Join(dir, "..x"), "/lesson/..x"},
+		{"main.go", "main.go"},
+		{"-o", "-o"},
+	}
+
+	for _, tt := range tests {
+		if got := hostToContainer(tt.arg, dir); got != tt.want {
+			t.Errorf("hostToContainer(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/viper"
+)
+
+// Language tells how to build and run a lesson file. Every argument is a
+// text/template with the fields of templateData, e.g. {{.File}}. The
+// commands run in the lesson directory, and the test case arguments are
+// appended to Run.
+type Language struct {
+	Build []string `mapstructure:"build"` // optional
+	Run   []string `mapstructure:"run"`
+}
+
+// templateData are the fields available in Language templates.
+type templateData struct {
+	File string // absolute path of the lesson file
+	Dir  string // lesson directory
+	Base string // file name, e.g. main.c
+	Name string // file name without extension, e.g. main
+	Bin  string // path for a built executable, e.g. <Dir>/main(.exe)
+}
+
+// defaultLanguages are keyed by file extension, like a course's
+// programming_language.
+var defaultLanguages = map[string]Language{
+	"go": {
+		Build: []string{"go", "build", "-o", "{{.Bin}}", "{{.File}}"},
+		Run:   []string{"{{.Bin}}"},
+	},
+	"py":  {Run: append(pythonCmd(), "{{.File}}")},
+	"js":  {Run: []string{"node", "{{.File}}"}},
+	"ts":  {Run: []string{"tsx", "{{.File}}"}},
+	"rb":  {Run: []string{"ruby", "{{.File}}"}},
+	"php": {Run: []string{"php", "{{.File}}"}},
+	"sh":  {Run: []string{"bash", "{{.File}}"}},
+	"lua": {Run: []string{"lua", "{{.File}}"}},
+	"c": {
+		Build: []string{"cc", "-std=c17", "-O2", "-Wall", "-o", "{{.Bin}}", "{{.File}}", "-lm"},
+		Run:   []string{"{{.Bin}}"},
+	},
+	"cpp": {
+		Build: []string{"c++", "-std=c++17", "-O2", "-Wall", "-o", "{{.Bin}}", "{{.File}}"},
+		Run:   []string{"{{.Bin}}"},
+	},
+	"rs": {
+		Build: []string{"rustc", "--edition", "2021", "-O", "-o", "{{.Bin}}", "{{.File}}"},
+		Run:   []string{"{{.Bin}}"},
+	},
+	"java": {
+		Build: []string{"javac", "-d", "{{.Dir}}", "{{.File}}"},
+		Run:   []string{"java", "-cp", "{{.Dir}}", "{{.Name}}"},
+	},
+	"kt": {
+		Build: []string{"kotlinc", "{{.File}}", "-include-runtime", "-d", "{{.Dir}}/{{.Name}}.jar"},
+		Run:   []string{"java", "-jar", "{{.Dir}}/{{.Name}}.jar"},
+	},
+}
+
+func pythonCmd() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"py", "-3"}
+	}
+	return []string{"python3"}
+}
+
+// LookupLanguage returns the runner definition for a file extension.
+// runner.languages.<extension> in config.yaml replaces the default one.
+func LookupLanguage(extension string) (Language, bool, error) {
+	var configured map[string]Language
+	if err := viper.UnmarshalKey("runner.languages", &configured); err != nil {
+		return Language{}, false, fmt.Errorf("invalid runner.languages in config: %w", err)
+	}
+	if l, ok := configured[extension]; ok {
+		if len(l.Run) == 0 {
+			return Language{}, false, fmt.Errorf("runner.languages.%s has no run command", extension)
+		}
+		return l, true, nil
+	}
+	l, ok := defaultLanguages[extension]
+	return l, ok, nil
+}
+
+// Expand fills in the templates for the lesson file at filePath.
+func (l Language) Expand(filePath string) (Language, error) {
+	dir := filepath.Dir(filePath)
+	base := filepath.Base(filePath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	bin := filepath.Join(dir, name)
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	data := templateData{File: filePath, Dir: dir, Base: base, Name: name, Bin: bin}
+
+	build, err := expandArgs(l.Build, data)
+	if err != nil {
+		return Language{}, err
+	}
+	run, err := expandArgs(l.Run, data)
+	if err != nil {
+		return Language{}, err
+	}
+	return Language{Build: build, Run: run}, nil
+}
+
+func expandArgs(args []string, data templateData) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		tmpl, err := template.New("arg").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid runner template %q: %w", arg, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("invalid runner template %q: %w", arg, err)
+		}
+		expanded[i] = b.String()
+	}
+	return expanded, nil
+}
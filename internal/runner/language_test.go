@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLookupLanguage(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("runner.languages", map[string]any{
+		"py":  map[string]any{"run": []string{"pypy3", "{{.File}}"}},
+		"zig": map[string]any{"run": []string{"zig", "run", "{{.File}}"}},
+		"rb":  map[string]any{"build": []string{"ruby", "-c", "{{.File}}"}},
+	})
+
+	tests := []struct {
+		extension string
+		wantRun   []string
+		wantOK    bool
+		wantErr   bool
+	}{
+		{"py", []string{"pypy3", "{{.File}}"}, true, false},
+		{"zig", []string{"zig", "run", "{{.File}}"}, true, false},
+		{"js", []string{"node", "{{.File}}"}, true, false},
+		{"rb", nil, false, true},
+		{"cobol", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		l, ok, err := LookupLanguage(tt.extension)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LookupLanguage(%s) error = %v, want error %v", tt.extension, err, tt.wantErr)
+			continue
+		}
+		if ok != tt.wantOK || !slices.Equal(l.Run, tt.wantRun) {
+			t.Errorf("LookupLanguage(%s) = %v, %v, want %v, %v", tt.extension, l.Run, ok, tt.wantRun, tt.wantOK)
+		}
+	}
+}
+
+func TestLanguageExpand(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hello")
+	file := filepath.Join(dir, "main.c")
+	bin := filepath.Join(dir, "main")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+
+	l, err := Language{
+		Build: []string{"cc", "-o", "{{.Bin}}", "{{.Base}}"},
+		Run:   []string{"{{.Bin}}", "{{.Dir}}/{{.Name}}.txt"},
+	}.Expand(file)
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if want := []string{"cc", "-o", bin, "main.c"}; !slices.Equal(l.Build, want) {
+		t.Errorf("Build = %v, want %v", l.Build, want)
+	}
+	if want := []string{bin, dir + "/main.txt"}; !slices.Equal(l.Run, want) {
+		t.Errorf("Run = %v, want %v", l.Run, want)
+	}
+
+	for _, arg := range []string{"{{.File", "{{.Missing}}"} {
+		if _, err := (Language{Run: []string{arg}}).Expand(file); err == nil {
+			t.Errorf("Expand(%q) = nil error", arg)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Local runs programs directly with the user's privileges. A program that
+// runs past the time limit is killed together with every process it started.
+type Local struct {
+	Limits Limits
+}
+
+func (l Local) Run(ctx context.Context, c Command) (Result, error) {
+	if len(c.Args) == 0 {
+		return Result{}, errors.New("no command to run")
+	}
+
+	runCtx := ctx
+	if l.Limits.WallTime > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, l.Limits.WallTime)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(runCtx, c.Args[0], c.Args[1:]...)
+	cmd.Dir = c.Dir
+	cmd.Stdin = strings.NewReader(c.Stdin)
+	output := newCapture(cmd, l.Limits.Output)
+	setProcessGroup(cmd)
+	// バックグラウンドに残った子プロセスが出力を開いたままでも待ち続けない
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if cmd.ProcessState != nil {
+		killProcessGroup(cmd)
+	}
+	res, err := output.result(err)
+	if err != nil {
+		return res, err
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		res.Limit = l.Limits.timeExceeded()
+	case output.truncated():
+		res.Limit = l.Limits.outputExceeded()
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLocalStopsAtWallTime(t *testing.T) {
+	l := Local{Limits: Limits{WallTime: 200 * time.Millisecond}}
+
+	start := time.Now()
+	res, err := l.Run(context.Background(), Command{Args: []string{"sleep", "10"}, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %s", elapsed)
+	}
+	if res.Limit != l.Limits.timeExceeded() {
+		t.Errorf("Limit = %q, want %q", res.Limit, l.Limits.timeExceeded())
+	}
+}
+
+func TestLocalCapsOutput(t *testing.T) {
+	l := Local{Limits: Limits{WallTime: 10 * time.Second, Output: 1024}}
+
+	res, err := l.Run(context.Background(), Command{Args: []string{"yes"}, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(res.Stdout) != 1024 {
+		t.Errorf("len(Stdout) = %d, want 1024", len(res.Stdout))
+	}
+	if res.Limit != l.Limits.outputExceeded() {
+		t.Errorf("Limit = %q, want %q", res.Limit, l.Limits.outputExceeded())
+	}
+}
+
+func TestLocalKillsBackgroundChildren(t *testing.T) {
+	l := Local{Limits: Limits{WallTime: 10 * time.Second}}
+
+	res, err := l.Run(context.Background(), Command{Args: []string{"sh", "-c", "sleep 30 & echo $!"}, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(res.Stdout))
+	if err != nil {
+		t.Fatalf("unexpected output %q", res.Stdout)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for alive(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("background process %d is still running", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// alive reports whether the process exists and is not a zombie waiting to
+// be reaped.
+func alive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		// /proc のない環境では kill(0) の結果だけで判断する
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
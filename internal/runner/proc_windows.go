@@ -0,0 +1,25 @@
+//go:build windows
+
+package runner
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// setProcessGroup makes cmd kill its whole process tree when the context of
+// cmd is done.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return taskkill(cmd)
+	}
+}
+
+// killProcessGroup kills what is left of the process tree of cmd.
+func killProcessGroup(cmd *exec.Cmd) {
+	taskkill(cmd)
+}
+
+func taskkill(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
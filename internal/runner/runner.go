@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Runner modes, selected with runner.mode in config.yaml.
+const (
+	ModeLocal     = "local"     // run with the user's privileges, only time and output are limited
+	ModeSandbox   = "sandbox"   // run in a Linux sandbox, see Sandbox
+	ModeContainer = "container" // run in a Docker or Podman image, see Container
+)
+
+// Default limits, configurable under runner.limits.* in config.yaml.
+const (
+	defaultCPUTime  = 10 * time.Second
+	defaultWallTime = 10 * time.Second
+	defaultMemoryMB = 1024
+	defaultOutputKB = 256
+	defaultFileMB   = 64
+)
+
+// defaultTimeouts are the wall-clock limits of languages that compile before
+// running, configurable under runner.timeouts.<language>.
+var defaultTimeouts = map[string]time.Duration{
+	"go":   30 * time.Second,
+	"ts":   20 * time.Second,
+	"c":    20 * time.Second,
+	"cpp":  30 * time.Second,
+	"rs":   30 * time.Second,
+	"java": 20 * time.Second,
+	"kt":   60 * time.Second,
+}
+
+// Command is a program run in a lesson directory.
+type Command struct {
+	Args  []string // program and arguments
+	Dir   string   // working directory, the only writable one in a sandbox
+	Stdin string
+}
+
+// Limits caps the resources of a program. The local runner only applies
+// WallTime and Output. Zero means no limit.
+type Limits struct {
+	CPUTime  time.Duration `json:"cpu_time"`
+	WallTime time.Duration `json:"wall_time"`
+	Memory   int64         `json:"memory"`    // bytes of data segment
+	Output   int64         `json:"output"`    // bytes of captured output
+	FileSize int64         `json:"file_size"` // bytes per written file
+}
+
+// Result is the outcome of a program that could be started.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int    // -1 when killed by a signal
+	Limit    string // the limit that stopped the program, empty if none
+}
+
+// Runner runs lesson programs. Run only fails when the program could not be
+// run at all; a non-zero exit is reported in the Result.
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (Result, error)
+}
+
+// FromConfig returns the runner selected in config.yaml for programs written
+// in language.
+func FromConfig(language string) (Runner, error) {
+	limits := LoadLimits(language)
+
+	mode := viper.GetString("runner.mode")
+	switch mode {
+	case "", ModeLocal:
+		return Local{Limits: limits}, nil
+	case ModeSandbox:
+		s, err := NewSandbox(limits)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case ModeContainer:
+		c, err := NewContainer(language, limits)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown runner mode '%s' (local, sandbox, container)", mode)
+}
+
+// LoadLimits reads runner.limits.* with the defaults applied. The wall-clock
+// limit of language is runner.timeouts.<language> if set.
+func LoadLimits(language string) Limits {
+	l := Limits{
+		CPUTime:  viper.GetDuration("runner.limits.cpu_time"),
+		WallTime: viper.GetDuration("runner.timeouts." + language),
+		Memory:   viper.GetInt64("runner.limits.memory_mb") << 20,
+		Output:   viper.GetInt64("runner.limits.output_kb") << 10,
+		FileSize: viper.GetInt64("runner.limits.file_mb") << 20,
+	}
+	if l.CPUTime <= 0 {
+		l.CPUTime = defaultCPUTime
+	}
+	if l.WallTime <= 0 {
+		l.WallTime = viper.GetDuration("runner.limits.wall_time")
+	}
+	if l.WallTime <= 0 {
+		l.WallTime = defaultTimeouts[language]
+	}
+	if l.WallTime <= 0 {
+		l.WallTime = defaultWallTime
+	}
+	if l.Memory <= 0 {
+		l.Memory = defaultMemoryMB << 20
+	}
+	if l.Output <= 0 {
+		l.Output = defaultOutputKB << 10
+	}
+	if l.FileSize <= 0 {
+		l.FileSize = defaultFileMB << 20
+	}
+	return l
+}
+
+// capture collects the stdout and stderr of a command, each capped at the
+// output limit.
+type capture struct {
+	stdout limitedBuffer
+	stderr limitedBuffer
+}
+
+func newCapture(cmd *exec.Cmd, max int64) *capture {
+	c := &capture{stdout: limitedBuffer{max: max}, stderr: limitedBuffer{max: max}}
+	cmd.Stdout = &c.stdout
+	cmd.Stderr = &c.stderr
+	return c
+}
+
+func (c *capture) truncated() bool {
+	return c.stdout.truncated || c.stderr.truncated
+}
+
+// result turns the error of exec.Cmd.Run into a Result. Only errors other
+// than a non-zero exit are returned.
+func (c *capture) result(err error) (Result, error) {
+	res := Result{Stdout: c.stdout.String(), Stderr: c.stderr.String()}
+	if errors.Is(err, errOutputLimit) || errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+		return res, nil
+	}
+	return res, err
+}
+
+func (l Limits) timeExceeded() string {
+	return fmt.Sprintf("time limit exceeded (%s)", l.WallTime)
+}
+
+func (l Limits) outputExceeded() string {
+	return fmt.Sprintf("output limit exceeded (%d KB)", l.Output>>10)
+}
+
+// limitedBuffer keeps the first max bytes written to it, or everything when
+// max is zero. Writing past the limit fails, which closes the pipe and stops
+// a program that keeps printing.
+type limitedBuffer struct {
+	buf       strings.Builder
+	max       int64
+	truncated bool
+}
+
+var errOutputLimit = errors.New("output limit exceeded")
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max <= 0 {
+		return b.buf.Write(p)
+	}
+	room := b.max - int64(b.buf.Len())
+	if int64(len(p)) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return int(max(room, 0)), errOutputLimit
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
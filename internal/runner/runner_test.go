@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadLimitsWallTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		config   map[string]any
+		want     time.Duration
+	}{
+		{"default", "py", nil, defaultWallTime},
+		{"language default", "kt", nil, 60 * time.Second},
+		{"configured limit", "kt", map[string]any{"runner.limits.wall_time": "5s"}, 5 * time.Second},
+		{"language timeout", "kt", map[string]any{"runner.limits.wall_time": "5s", "runner.timeouts.kt": "90s"}, 90 * time.Second},
+		{"other language timeout", "py", map[string]any{"runner.timeouts.kt": "90s"}, defaultWallTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			for k, v := range tt.config {
+				viper.Set(k, v)
+			}
+			if got := LoadLimits(tt.language).WallTime; got != tt.want {
+				t.Errorf("WallTime = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLimitsDefaults(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("runner.limits.output_kb", 8)
+
+	l := LoadLimits("go")
+	if l.Output != 8<<10 {
+		t.Errorf("Output = %d, want %d", l.Output, 8<<10)
+	}
+	if l.CPUTime != defaultCPUTime || l.Memory != defaultMemoryMB<<20 || l.FileSize != defaultFileMB<<20 {
+		t.Errorf("defaults not applied: %+v", l)
+	}
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SandboxCommand is the hidden progoat subcommand that sets up the sandbox
+// inside the new namespaces and then executes the lesson program.
+const SandboxCommand = "__sandbox"
+
+// Sandbox runs programs in fresh user, mount, network, PID, IPC and UTS
+// namespaces: there is no network, the filesystem is read-only except for the
+// lesson directory, CacheDir and a private /tmp, and the limits are enforced
+// with rlimits and a wall-clock timeout.
+type Sandbox struct {
+	Limits   Limits
+	CacheDir string // writable toolchain caches (Go build cache, HOME)
+}
+
+// sandboxSpec is passed from Run to the sandbox subcommand.
+type sandboxSpec struct {
+	Dir      string `json:"dir"`
+	CacheDir string `json:"cache_dir"`
+	Limits   Limits `json:"limits"`
+}
+
+// The sandbox subcommand reports setup failures with this exit code and
+// prefix, so Run can tell them apart from the program's own errors.
+const (
+	setupFailedCode  = 125
+	setupErrorPrefix = "progoat sandbox: "
+)
+
+// SandboxMain is the entry point of the sandbox subcommand. args are the
+// spec followed by the program and its arguments. It only returns by exiting.
+func SandboxMain(args []string) {
+	err := sandboxExec(args)
+	fmt.Fprintln(os.Stderr, setupErrorPrefix+err.Error())
+	os.Exit(setupFailedCode)
+}
+
+func isSetupError(res Result) (string, bool) {
+	if res.ExitCode != setupFailedCode || !strings.HasPrefix(res.Stderr, setupErrorPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(res.Stderr, setupErrorPrefix)), true
+}
+
+// outOfMemoryMarkers are printed by common runtimes when an allocation fails.
+var outOfMemoryMarkers = []string{
+	"out of memory",
+	"cannot allocate memory",
+	"memoryerror",
+	"failed to reserve",
+	"allocation failed",
+}
+
+func looksOutOfMemory(output string) bool {
+	output = strings.ToLower(output)
+	for _, marker := range outOfMemoryMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,338 @@
+//go:build linux
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// NewSandbox returns a sandbox that keeps its caches in the user cache
+// directory.
+func NewSandbox(limits Limits) (*Sandbox, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir = filepath.Join(cacheDir, "progoat", "sandbox")
+	if err := os.MkdirAll(filepath.Join(cacheDir, "home"), 0700); err != nil {
+		return nil, err
+	}
+	return &Sandbox{Limits: limits, CacheDir: cacheDir}, nil
+}
+
+func (s *Sandbox) Run(ctx context.Context, c Command) (Result, error) {
+	if len(c.Args) == 0 {
+		return Result{}, errors.New("no command to run")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return Result{}, err
+	}
+	spec, err := json.Marshal(sandboxSpec{Dir: c.Dir, CacheDir: s.CacheDir, Limits: s.Limits})
+	if err != nil {
+		return Result{}, err
+	}
+
+	runCtx := ctx
+	if s.Limits.WallTime > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, s.Limits.WallTime)
+		defer cancel()
+	}
+
+	// The sandbox subcommand is PID 1 of the new namespace, so killing it on
+	// timeout also kills everything the program started
+	cmd := exec.CommandContext(runCtx, exe, append([]string{SandboxCommand, string(spec)}, c.Args...)...)
+	cmd.Dir = c.Dir
+	cmd.Stdin = strings.NewReader(c.Stdin)
+	output := newCapture(cmd, s.Limits.Output)
+	statusR, statusW, err := os.Pipe()
+	if err != nil {
+		return Result{}, err
+	}
+	defer statusR.Close()
+	cmd.ExtraFiles = []*os.File{statusW} // statusFD
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+
+	err = cmd.Run()
+	statusW.Close()
+	if cmd.ProcessState == nil {
+		return Result{}, fmt.Errorf("could not start the sandbox (are unprivileged user namespaces enabled?): %w", err)
+	}
+	res, err := output.result(err)
+	if err != nil {
+		return res, err
+	}
+	status, _ := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if program, ok := readStatus(statusR); ok {
+		status = program
+		res.ExitCode = status.ExitStatus()
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	if msg, ok := isSetupError(res); ok {
+		return Result{}, errors.New("sandbox: " + msg)
+	}
+
+	cpu := cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+	res.Limit = s.limitHit(runCtx, status, cpu, output.truncated(), res)
+	return res, nil
+}
+
+// readStatus reads the wait status of the program written by runAsInit. It
+// is missing when the sandbox was killed or could not start the program.
+func readStatus(r io.Reader) (syscall.WaitStatus, bool) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(b), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return syscall.WaitStatus(n), true
+}
+
+// limitHit names the limit that stopped the program, if any. cpu includes
+// every process of the sandbox.
+func (s *Sandbox) limitHit(ctx context.Context, status syscall.WaitStatus, cpu time.Duration, truncated bool, res Result) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return s.Limits.timeExceeded()
+	case status.Signaled() && (status.Signal() == syscall.SIGXCPU ||
+		status.Signal() == syscall.SIGKILL && s.Limits.CPUTime > 0 && cpu >= s.Limits.CPUTime):
+		return fmt.Sprintf("CPU time limit exceeded (%s)", s.Limits.CPUTime)
+	case status.Signaled() && status.Signal() == syscall.SIGXFSZ:
+		return fmt.Sprintf("file size limit exceeded (%d MB)", s.Limits.FileSize>>20)
+	case truncated:
+		return s.Limits.outputExceeded()
+	case res.ExitCode != 0 && looksOutOfMemory(res.Stderr):
+		return fmt.Sprintf("memory limit exceeded (%d MB)", s.Limits.Memory>>20)
+	}
+	return ""
+}
+
+// sandboxExec runs inside the new namespaces as root of the user namespace.
+// It isolates the filesystem, applies the limits, drops every capability and
+// runs the program.
+func sandboxExec(args []string) error {
+	if len(args) < 2 {
+		return errors.New("missing spec or command")
+	}
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return fmt.Errorf("invalid spec: %w", err)
+	}
+
+	if err := isolateFilesystem(spec.Dir, spec.CacheDir); err != nil {
+		return err
+	}
+	if err := os.Chdir(spec.Dir); err != nil {
+		return err
+	}
+	path, err := exec.LookPath(args[1])
+	if err != nil {
+		return err
+	}
+	if err := setLimits(spec.Limits); err != nil {
+		return err
+	}
+	if err := dropPrivileges(); err != nil {
+		return err
+	}
+	return runAsInit(path, args[1:], sandboxEnv(spec.CacheDir))
+}
+
+// statusFD is the pipe on which runAsInit reports the wait status of the
+// program to Run.
+const statusFD = 3
+
+// runAsInit starts the program as a child and waits for it like a minimal
+// init, reaping the orphans it leaves behind. The kernel drops signals to
+// PID 1 that it has no handler for, so the program must not be PID 1 or it
+// would never get SIGXCPU, SIGXFSZ or SIGPIPE. Exiting afterwards kills the
+// rest of the namespace.
+func runAsInit(path string, args, env []string) error {
+	// プログラムにはパイプを引き継がない
+	unix.CloseOnExec(statusFD)
+	status := os.NewFile(statusFD, "status")
+
+	cmd := &exec.Cmd{Path: path, Args: args, Env: env, Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	for {
+		var ws unix.WaitStatus
+		pid, err := unix.Wait4(-1, &ws, 0, nil)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if pid != cmd.Process.Pid {
+			continue
+		}
+
+		fmt.Fprint(status, uint32(ws))
+		status.Close()
+		if ws.Signaled() {
+			os.Exit(128 + int(ws.Signal()))
+		}
+		os.Exit(ws.ExitStatus())
+	}
+}
+
+// isolateFilesystem makes every mount read-only except dir, cacheDir and a
+// fresh /tmp, and mounts a /proc that shows only the sandboxed processes.
+func isolateFilesystem(dir, cacheDir string) error {
+	// 親の名前空間にマウントが伝播しないようにする
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+
+	// 書き込み可能なディレクトリは /tmp の下にあっても見えるように、
+	// tmpfs をマウントする前に開いておく
+	writable := []string{dir, cacheDir}
+	fds := make([]int, len(writable))
+	for i, p := range writable {
+		fd, err := unix.Open(p, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", p, err)
+		}
+		defer unix.Close(fd)
+		fds[i] = fd
+	}
+
+	if err := unix.Mount("tmpfs", "/tmp", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "size=64m,mode=1777"); err != nil {
+		return fmt.Errorf("mount /tmp: %w", err)
+	}
+	for i, p := range writable {
+		if err := os.MkdirAll(p, 0700); err != nil {
+			return err
+		}
+		src := fmt.Sprintf("/proc/self/fd/%d", fds[i])
+		if err := unix.Mount(src, p, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind %s: %w", p, err)
+		}
+	}
+	writable = append(writable, "/tmp")
+
+	// 新しい PID 名前空間のプロセスだけが見えるよう /proc をマウントし直す
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount /proc: %w", err)
+	}
+
+	err := unix.MountSetattr(-1, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY})
+	if errors.Is(err, unix.ENOSYS) {
+		return errors.New("the sandbox needs Linux 5.12 or later")
+	}
+	if err != nil {
+		return fmt.Errorf("make / read-only: %w", err)
+	}
+	for _, p := range writable {
+		if err := unix.MountSetattr(-1, p, 0, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY}); err != nil {
+			return fmt.Errorf("make %s writable: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func setLimits(l Limits) error {
+	set := func(resource int, cur, max uint64) error {
+		return unix.Setrlimit(resource, &unix.Rlimit{Cur: cur, Max: max})
+	}
+
+	if l.CPUTime > 0 {
+		// ソフトリミットで SIGXCPU、1秒後にハードリミットで SIGKILL
+		seconds := uint64((l.CPUTime + 999_999_999) / 1_000_000_000)
+		if err := set(unix.RLIMIT_CPU, seconds, seconds+1); err != nil {
+			return fmt.Errorf("limit CPU time: %w", err)
+		}
+	}
+	if l.Memory > 0 {
+		if err := set(unix.RLIMIT_DATA, uint64(l.Memory), uint64(l.Memory)); err != nil {
+			return fmt.Errorf("limit memory: %w", err)
+		}
+	}
+	if l.FileSize > 0 {
+		if err := set(unix.RLIMIT_FSIZE, uint64(l.FileSize), uint64(l.FileSize)); err != nil {
+			return fmt.Errorf("limit file size: %w", err)
+		}
+	}
+	return set(unix.RLIMIT_CORE, 0, 0)
+}
+
+// dropPrivileges clears the capability bounding set and stops root of the
+// user namespace from regaining capabilities on exec, so the program cannot
+// undo the read-only mounts.
+func dropPrivileges() error {
+	const (
+		secbitNoRoot       = 1 << 0
+		secbitNoRootLocked = 1 << 1
+	)
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	for c := 0; c <= unix.CAP_LAST_CAP; c++ {
+		err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0)
+		if err != nil && !errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("drop capabilities: %w", err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_SET_SECUREBITS, secbitNoRoot|secbitNoRootLocked, 0, 0, 0); err != nil {
+		return fmt.Errorf("set securebits: %w", err)
+	}
+	return nil
+}
+
+// sandboxEnv is a minimal environment that points the toolchains' caches at
+// the writable cache directory and keeps the toolchains themselves findable.
+func sandboxEnv(cacheDir string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + filepath.Join(cacheDir, "home"),
+		"TMPDIR=/tmp",
+		"XDG_CACHE_HOME=" + cacheDir,
+		"GOCACHE=" + filepath.Join(cacheDir, "go-build"),
+		"GOPATH=" + filepath.Join(cacheDir, "go"),
+		"GOTOOLCHAIN=local",
+	}
+	for _, key := range []string{"LANG", "LC_ALL", "TERM", "GOROOT", "JAVA_HOME"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+
+	// rustup のツールチェーンは本来の HOME の下にある
+	home, _ := os.UserHomeDir()
+	for key, dir := range map[string]string{"RUSTUP_HOME": ".rustup", "CARGO_HOME": ".cargo"} {
+		v, ok := os.LookupEnv(key)
+		if !ok {
+			v = filepath.Join(home, dir)
+		}
+		env = append(env, key+"="+v)
+	}
+	return env
+}
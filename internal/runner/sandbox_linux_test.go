@@ -0,0 +1,27 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestReadStatus(t *testing.T) {
+	exited := syscall.WaitStatus(3 << 8)
+	signaled := syscall.WaitStatus(syscall.SIGXFSZ)
+	for _, want := range []syscall.WaitStatus{exited, signaled} {
+		got, ok := readStatus(strings.NewReader(fmt.Sprint(uint32(want))))
+		if !ok || got != want {
+			t.Errorf("readStatus(%d) = %d, %v", want, got, ok)
+		}
+	}
+	if !exited.Exited() || exited.ExitStatus() != 3 || signaled.Signal() != syscall.SIGXFSZ {
+		t.Fatal("unexpected wait status encoding")
+	}
+
+	// 強制終了されたときは何も書かれない
+	if _, ok := readStatus(strings.NewReader("")); ok {
+		t.Error("readStatus of an empty pipe succeeded")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package runner
+
+import (
+	"context"
+	"errors"
+)
+
+var errSandboxUnsupported = errors.New("the sandbox runner is only available on Linux; set runner.mode to local")
+
+func NewSandbox(limits Limits) (*Sandbox, error) {
+	return nil, errSandboxUnsupported
+}
+
+func (s *Sandbox) Run(ctx context.Context, c Command) (Result, error) {
+	return Result{}, errSandboxUnsupported
+}
+
+func sandboxExec(args []string) error {
+	return errSandboxUnsupported
+}
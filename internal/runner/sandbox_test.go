@@ -0,0 +1,34 @@
+package runner
+
+import "testing"
+
+func TestIsSetupError(t *testing.T) {
+	tests := []struct {
+		res     Result
+		wantMsg string
+		want    bool
+	}{
+		{Result{ExitCode: setupFailedCode, Stderr: setupErrorPrefix + "mount /proc: operation not permitted\n"}, "mount /proc: operation not permitted", true},
+		{Result{ExitCode: setupFailedCode, Stderr: "panic: boom\n"}, "", false},
+		{Result{ExitCode: 1, Stderr: setupErrorPrefix + "x\n"}, "", false},
+	}
+
+	for _, tt := range tests {
+		msg, ok := isSetupError(tt.res)
+		if ok != tt.want || msg != tt.wantMsg {
+			t.Errorf("isSetupError(%+v) = %q, %v, want %q, %v", tt.res, msg, ok, tt.wantMsg, tt.want)
+		}
+	}
+}
+
+func TestLooksOutOfMemory(t *testing.T) {
+	for stderr, want := range map[string]bool{
+		"fatal error: runtime: out of memory":             true,
+		"Traceback (most recent call last):\nMemoryError": true,
+		"panic: index out of range":                       false,
+	} {
+		if got := looksOutOfMemory(stderr); got != want {
+			t.Errorf("looksOutOfMemory(%q) = %v, want %v", stderr, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/llm"
+	"github.com/minotto165/progoat/internal/tutor"
+)
+
+type chatMsg struct {
+	visit    int
+	question string
+	answer   string
+	err      error
+}
+
+func newChatInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "Ask a question about this lesson"
+	input.Prompt = "> "
+	return input
+}
+
+// openChat shows the tutor chat of the lesson, resuming the saved
+// conversation.
+func (m *model) openChat() tea.Cmd {
+	if m.chat == nil {
+		chat, err := tutor.Open(course.LessonDir(m.opts.CoursePath, m.currentLesson()))
+		if err != nil {
+			m.status = "Could not open the chat: " + err.Error()
+			return nil
+		}
+		m.chat = chat
+	}
+	m.chatting = true
+	m.refresh()
+	m.chatView.GotoBottom()
+	return m.chatInput.Focus()
+}
+
+func (m *model) handleChatKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.chatting = false
+		m.chatInput.Blur()
+		return nil
+	case "enter":
+		question := strings.TrimSpace(m.chatInput.Value())
+		if question == "" || m.busy != "" {
+			return nil
+		}
+		m.chatInput.Reset()
+		return m.ask(question)
+	case "up", "down", "pgup", "pgdown":
+		var cmd tea.Cmd
+		m.chatView, cmd = m.chatView.Update(msg)
+		return cmd
+	}
+	var cmd tea.Cmd
+	m.chatInput, cmd = m.chatInput.Update(msg)
+	return cmd
+}
+
+// ask sends the question with the lesson, the current code and the last run.
+func (m *model) ask(question string) tea.Cmd {
+	m.asking = question
+	m.busy = "Thinking..."
+	m.refresh()
+	m.chatView.GotoBottom()
+
+	ctx, c, l, coursePath, visit := m.lessonCtx(), m.opts.Course, m.currentLesson(), m.opts.CoursePath, m.visit
+	history := m.chat.Messages
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		answer, err := tutor.Answer(ctx, tutor.LessonContext(c, l, coursePath), history, question)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return chatMsg{visit: visit, question: question, answer: answer, err: err}
+	})
+}
+
+// showAnswer adds the answer to the conversation and saves it. A failed
+// question is only reported and can be asked again.
+func (m *model) showAnswer(msg chatMsg) tea.Cmd {
+	m.asking = ""
+	if msg.err != nil {
+		m.status = "The tutor could not answer: " + msg.err.Error()
+		m.chatInput.SetValue(msg.question)
+	} else if err := m.chat.Add(msg.question, msg.answer); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.refresh()
+	m.chatView.GotoBottom()
+	return nil
+}
+
+func chatMarkdown(messages []llm.ChatMessage, asking string) string {
+	if len(messages) == 0 && asking == "" {
+		return "*Ask anything about this lesson. The tutor sees the slides, the task, your code and the output of your last run.*"
+	}
+
+	var b strings.Builder
+	for _, m := range messages {
+		if m.Role == llm.ChatUser {
+			fmt.Fprintf(&b, "### 🙋 You\n%s\n\n", m.Content)
+		} else {
+			fmt.Fprintf(&b, "### 🐐 Tutor\n%s\n\n", m.Content)
+		}
+	}
+	if asking != "" {
+		fmt.Fprintf(&b, "### 🙋 You\n%s\n\n", asking)
+	}
+	return b.String()
+}
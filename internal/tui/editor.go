@@ -0,0 +1,23 @@
+package tui
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// editorCommand returns editor.command from config.yaml, $VISUAL or $EDITOR,
+// falling back to vi (notepad on Windows) like git does.
+func editorCommand() []string {
+	for _, command := range []string{viper.GetString("editor.command"), os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(command); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
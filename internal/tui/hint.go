@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/judge"
+	"github.com/minotto165/progoat/internal/llm"
+)
+
+type hintMsg struct {
+	visit int
+	level int
+	hint  string
+	err   error
+}
+
+// hint asks the AI for the next hint of the lesson. Hints escalate from a
+// nudge to a partial snippet; after that, every hint stays a snippet.
+func (m *model) hint() tea.Cmd {
+	l := m.currentLesson()
+	level := min(m.hintsUsed[l.ID]+1, llm.MaxHintLevel)
+
+	m.busy = "Thinking of a hint..."
+	m.status = ""
+	ctx, c, visit, filePath := m.lessonCtx(), m.opts.Course, m.visit, m.filePath()
+	attempts, previous := strings.Join(m.attempts, "\n"), m.hints
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		code, err := os.ReadFile(filePath)
+		if err != nil {
+			return hintMsg{visit: visit, err: err}
+		}
+		response, err := llm.GenerateHint(ctx, level, l.TaskDescription, string(code), attempts, previous, c.Title, l.Title)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return hintMsg{visit: visit, err: err}
+		}
+		var hint struct {
+			Hint string `json:"hint"`
+		}
+		if err := json.Unmarshal([]byte(response), &hint); err != nil {
+			return hintMsg{visit: visit, err: err}
+		}
+		return hintMsg{visit: visit, level: level, hint: hint.Hint}
+	})
+}
+
+// showHint adds the hint under the task and counts it in progress.json. A
+// failed hint is only reported, since the lesson can go on without it.
+func (m *model) showHint(msg hintMsg) tea.Cmd {
+	if msg.err != nil {
+		m.status = "Could not get a hint: " + msg.err.Error()
+		return nil
+	}
+
+	c, l := m.opts.Course, m.currentLesson()
+	used, err := course.RecordHint(c.ID, l.ID, m.opts.ProgressPath, len(c.Lessons))
+	if err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.hintsUsed[l.ID] = used
+
+	m.hints = append(m.hints, fmt.Sprintf("### 💡 Hint %d (%s)\n%s", used, llm.HintName(msg.level), msg.hint))
+	m.focus = paneTask
+	m.refresh()
+	m.taskView.GotoBottom()
+	return nil
+}
+
+// attemptSummary describes a judged submission in one line for the hints.
+func attemptSummary(n int, lesson course.Lesson, v judge.Verdict) string {
+	result := "wrong"
+	if v.IsCorrect {
+		result = "correct"
+	}
+	_, status := judge.Status(lesson, v.Results)
+	summary := fmt.Sprintf("Attempt %d: %s; %s", n, result, strings.TrimLeft(status, "✅❌✔ "))
+	if v.Score != nil {
+		summary += fmt.Sprintf("; scored %d/100", v.Score.Overall)
+	}
+	if v.Advice != "" {
+		summary += "; advice given: " + v.Advice
+	}
+	return summary
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/judge"
+	"github.com/minotto165/progoat/internal/tutor"
+	"github.com/minotto165/progoat/internal/ui"
+)
+
+func taskMarkdown(lesson course.Lesson, filePath string) string {
+	return fmt.Sprintf("%s\n%s\n\n**File to edit:**\n```text\n%s\n```\n*DISCLAIMER: AI-generated code is executed locally. Use at your own risk.*",
+		"## Task:",
+		lesson.TaskDescription,
+		filePath,
+	)
+}
+
+// codeMarkdown shows the current code of a lesson, e.g. when revisiting a
+// completed one.
+func codeMarkdown(language, filePath string) string {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\n\n## Your Code\n```%s\n%s\n```\n", language, strings.TrimRight(string(code), "\n"))
+}
+
+func solutionMarkdown(language string, s tutor.Solution) string {
+	return fmt.Sprintf("\n\n## 🔑 Reference Solution\n%s\n```%s\n%s\n```\n", s.Explanation, language, strings.TrimRight(s.Code, "\n"))
+}
+
+// resultsMarkdown shows the verdict and advice first, so they are visible
+// without scrolling, followed by the output and the test results. verdict
+// is nil while the AI judge is thinking.
+func resultsMarkdown(lesson course.Lesson, results []judge.Result, verdict *judge.Verdict) string {
+	var b strings.Builder
+
+	if verdict != nil {
+		if verdict.IsCorrect {
+			b.WriteString("## 🎉 CORRECT!  \n\n")
+		} else {
+			b.WriteString("## ❌ WRONG...  \n\n")
+		}
+		if verdict.OutputMatched {
+			b.WriteString("✅ All test cases passed.  \n\n")
+		}
+		if verdict.Advice != "" {
+			b.WriteString("### AI Advice  \n> " + verdict.Advice + "\n\n")
+		}
+		if verdict.Score != nil {
+			b.WriteString(scoreMarkdown(lesson, *verdict.Score))
+		}
+	}
+
+	first := results[0]
+	if first.Output != "" || first.Stderr != "" || first.Failure() != "" {
+		b.WriteString("## Execution Output\n")
+		if first.Output != "" {
+			b.WriteString("> " + first.Output)
+		}
+		if first.Stderr != "" {
+			b.WriteString("\n\n### Errors\n```text\n" + strings.TrimRight(first.Stderr, "\n") + "\n```")
+		}
+		if failure := first.Failure(); failure != "" {
+			b.WriteString("\n\n⚠️ **" + failure + "**")
+		}
+		b.WriteString("\n\n")
+	}
+
+	if len(lesson.Cases()) > 0 {
+		b.WriteString(testResultsMarkdown(results))
+	}
+	return b.String()
+}
+
+// scoreMarkdown renders the rubric breakdown of the AI judge. The pass
+// threshold is shown only when it decides, i.e. without test cases.
+func scoreMarkdown(lesson course.Lesson, s course.Score) string {
+	md := fmt.Sprintf("### Score: %d / 100", s.Overall)
+	if len(lesson.Cases()) == 0 {
+		md += fmt.Sprintf(" (pass: %d)", judge.PassThreshold())
+	}
+	md += "\n\n| Criterion | Score |\n|---|---|\n"
+	for _, c := range []struct {
+		name  string
+		score int
+	}{
+		{"Correctness", s.Correctness},
+		{"Style", s.Style},
+		{"Idiomatic use", s.Idiomatic},
+		{"Edge cases", s.EdgeCases},
+	} {
+		md += fmt.Sprintf("| %s | %s %3d |\n", c.name, ui.DrawProgressbar(float64(c.score), 10), c.score)
+	}
+	return md + "\n"
+}
+
+// testResultsMarkdown renders a pass/fail table, followed by the expected and
+// actual output of every visible failed case.
+func testResultsMarkdown(results []judge.Result) string {
+	md := "## Test Results\n\n| # | Input | Result |\n|---|---|---|\n"
+	details := ""
+
+	for i, r := range results {
+		input := "-"
+		switch {
+		case r.Case.Hidden:
+			input = "(hidden)"
+		case len(r.Case.Args) > 0 || r.Case.Stdin != "":
+			input = strings.TrimSpace(strings.Join(r.Case.Args, " ") + " " + strings.ReplaceAll(r.Case.Stdin, "\n", "⏎"))
+			input = "`" + strings.ReplaceAll(input, "|", "\\|") + "`"
+		}
+
+		result := "✅ Passed"
+		if !r.Passed {
+			result = "❌ Failed"
+			if failure := r.Failure(); failure != "" {
+				result += " (" + failure + ")"
+			}
+			if !r.Case.Hidden {
+				details += fmt.Sprintf("### Case %d\n**Expected:**\n```text\n%s\n```\n**Actual:**\n```text\n%s\n```\n", i+1, r.Case.ExpectedOutput, r.Output)
+				if r.Stderr != "" && r.Phase == judge.PhaseRun {
+					details += fmt.Sprintf("**Errors:**\n```text\n%s\n```\n", strings.TrimRight(r.Stderr, "\n"))
+				}
+			}
+		}
+
+		md += fmt.Sprintf("| %d | %s | %s |\n", i+1, input, result)
+	}
+
+	return md + "\n" + details
+}
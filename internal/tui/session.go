@@ -0,0 +1,646 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fsnotify/fsnotify"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/history"
+	"github.com/minotto165/progoat/internal/judge"
+	"github.com/minotto165/progoat/internal/tutor"
+	"github.com/minotto165/progoat/internal/usage"
+)
+
+// Options configure a learning session.
+type Options struct {
+	Course       course.Course
+	CoursePath   string
+	ProgressPath string
+	StartLesson  int  // index of the first lesson to show
+	Watch        bool // rerun the local checks every time the lesson file is saved
+}
+
+// Run shows the session full-screen until the user quits or finishes the
+// course.
+func Run(ctx context.Context, opts Options) error {
+	progress, err := course.LoadProgress(opts.Course.ID, opts.ProgressPath)
+	if err != nil {
+		return err
+	}
+
+	// 終了時に実行中のプログラムや AI の呼び出しも止める
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// glamour の自動判定は端末に問い合わせるので、Bubble Tea が入力を読み始める前に決めておく
+	style := styles.LightStyle
+	if lipgloss.HasDarkBackground() {
+		style = styles.DarkStyle
+	}
+
+	m := newModel(ctx, opts, progress, style)
+	defer m.leaveLesson()
+
+	if _, err := tea.NewProgram(m, tea.WithContext(ctx), tea.WithAltScreen()).Run(); err != nil {
+		return err
+	}
+	return m.err
+}
+
+type phase int
+
+const (
+	phaseSlides phase = iota
+	phaseTask
+	phaseDone // the course is completed
+)
+
+type pane int
+
+const (
+	paneTask pane = iota
+	paneResults
+)
+
+const watchDebounce = 300 * time.Millisecond
+
+type model struct {
+	ctx       context.Context
+	opts      Options
+	completed map[string]bool
+
+	lesson int // index in opts.Course.Lessons
+	slide  int
+	phase  phase
+	focus  pane // the pane that scrolls in the task phase
+
+	visit       int // counts opened lessons, so results of a lesson left behind are dropped
+	visitCtx    context.Context
+	cancelVisit context.CancelFunc // stops what still runs for the previous lesson
+
+	picking bool // the lesson picker is open
+	pick    int  // lesson under the cursor of the picker
+
+	chatting  bool // the tutor chat is open
+	chat      *tutor.Conversation
+	asking    string // question waiting for its answer
+	chatView  viewport.Model
+	chatInput textinput.Model
+
+	slideView   viewport.Model
+	taskView    viewport.Model
+	resultsView viewport.Model
+	spinner     spinner.Model
+
+	busy     string // what is running, e.g. "Judging..."; nothing else starts meanwhile
+	status   string // one line above the key help
+	results  []judge.Result
+	code     []byte // the code the results were run from, for the history
+	verdict  *judge.Verdict
+	attempts []string // summaries of the submissions in this visit, for hints
+
+	hints     []string       // hints shown in this visit
+	hintsUsed map[string]int // lesson ID -> hints shown, as in progress.json
+
+	solution *tutor.Solution // the reference solution, once revealed
+	givingUp bool            // s was pressed once; pressing it again reveals the solution
+	failed   map[string]int  // lesson ID -> wrong submissions, as in progress.json
+	revealed map[string]bool // lessons whose solution was revealed
+
+	watcher *fsnotify.Watcher
+	changes int // counts saves so only the last one in a burst is checked
+
+	width, height int
+	style         string // glamour style
+	renderer      *glamour.TermRenderer
+	wrap          int // word wrap of renderer
+
+	err error // returned by Run
+}
+
+func newModel(ctx context.Context, opts Options, progress course.Progress, style string) *model {
+	m := &model{
+		ctx:         ctx,
+		opts:        opts,
+		completed:   map[string]bool{},
+		hintsUsed:   map[string]int{},
+		failed:      map[string]int{},
+		revealed:    map[string]bool{},
+		lesson:      min(max(opts.StartLesson, 0), len(opts.Course.Lessons)-1),
+		slideView:   viewport.New(0, 0),
+		taskView:    viewport.New(0, 0),
+		resultsView: viewport.New(0, 0),
+		chatView:    viewport.New(0, 0),
+		chatInput:   newChatInput(),
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot)),
+		style:       style,
+	}
+	for _, id := range progress.CompletedLessons {
+		m.completed[id] = true
+	}
+	for id, n := range progress.Hints {
+		m.hintsUsed[id] = n
+	}
+	for id, n := range progress.FailedAttempts {
+		m.failed[id] = n
+	}
+	for _, id := range progress.SolutionRevealed {
+		m.revealed[id] = true
+	}
+	return m
+}
+
+// Messages of the commands started by the session. Results that arrive
+// after the user opened another lesson are dropped.
+type (
+	ranMsg struct {
+		visit   int
+		results []judge.Result
+		code    []byte
+		check   bool  // a quiet check of watch mode
+		err     error // the program could not be run
+	}
+	verdictMsg struct {
+		visit   int
+		verdict judge.Verdict
+		err     error
+	}
+	editedMsg      struct{ err error }
+	fileChangedMsg struct{ watcher *fsnotify.Watcher }
+	debouncedMsg   struct{ changes int }
+	errMsg         struct{ err error }
+)
+
+func (m *model) Init() tea.Cmd {
+	return m.openLesson(m.lesson)
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		m.refresh()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+
+	case spinner.TickMsg:
+		if m.busy == "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case ranMsg:
+		if msg.visit != m.visit {
+			return m, nil
+		}
+		m.busy = ""
+		if msg.err != nil {
+			// ツールチェーンやイメージがないだけなので、セッションは続ける
+			m.status = fmt.Sprintf("Could not run the program: %v. Press Enter to try again.", msg.err)
+			if msg.check {
+				m.status = time.Now().Format(time.TimeOnly) + " " + m.status
+			}
+			return m, nil
+		}
+		if err := tutor.SaveLastRun(course.LessonDir(m.opts.CoursePath, m.currentLesson()), m.currentLesson(), msg.results); err != nil {
+			return m, func() tea.Msg { return errMsg{err} }
+		}
+		if msg.check {
+			passed, status := judge.Status(m.currentLesson(), msg.results)
+			m.status = time.Now().Format(time.TimeOnly) + " " + status
+			if !passed {
+				return m, nil
+			}
+		}
+		return m, m.judged(msg.results, msg.code)
+
+	case verdictMsg:
+		if msg.visit != m.visit {
+			return m, nil
+		}
+		m.busy = ""
+		if msg.err != nil {
+			// 判定できなくてもセッションは続け、Enter でやり直せるようにする
+			m.status = fmt.Sprintf("Could not judge: %v. Press Enter to try again.", msg.err)
+			return m, nil
+		}
+		return m, m.showVerdict(msg.verdict)
+
+	case editedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not run the editor (%s): %v. Set editor.command in config.yaml or $EDITOR.",
+				strings.Join(editorCommand(), " "), msg.err)
+			return m, nil
+		}
+		// watch モードでは保存を検知して確認するので、ここでは何もしない
+		if m.opts.Watch || m.busy != "" {
+			return m, nil
+		}
+		return m, m.judge()
+
+	case fileChangedMsg:
+		if msg.watcher != m.watcher {
+			return m, nil
+		}
+		m.changes++
+		return m, tea.Batch(waitForChange(m.watcher, m.filePath()), m.debounce())
+
+	case debouncedMsg:
+		if msg.changes != m.changes || m.phase != phaseTask || m.verdict != nil && m.verdict.IsCorrect {
+			return m, nil
+		}
+		if m.busy != "" {
+			// 実行中なら終わってから確認する
+			return m, m.debounce()
+		}
+		m.busy = "Checking..."
+		return m, tea.Batch(m.spinner.Tick, m.run(true))
+
+	case chatMsg:
+		if msg.visit != m.visit {
+			return m, nil
+		}
+		m.busy = ""
+		return m, m.showAnswer(msg)
+
+	case solutionMsg:
+		if msg.visit != m.visit {
+			return m, nil
+		}
+		m.busy = ""
+		return m, m.showSolution(msg)
+
+	case hintMsg:
+		if msg.visit != m.visit {
+			return m, nil
+		}
+		m.busy = ""
+		return m, m.showHint(msg)
+
+	case errMsg:
+		m.err = msg.err
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	if m.chatting {
+		return m.handleChatKey(msg)
+	}
+	if msg.String() == "q" {
+		return tea.Quit
+	}
+	if m.picking {
+		return m.handlePickerKey(msg)
+	}
+
+	switch msg.String() {
+	case "g":
+		m.picking = true
+		m.pick = m.lesson
+		return nil
+	case "a":
+		if m.phase != phaseDone {
+			return m.openChat()
+		}
+	}
+
+	switch m.phase {
+	case phaseSlides:
+		switch msg.String() {
+		case "right", "l", "enter", " ":
+			if m.slide+1 < len(m.currentLesson().Slides) {
+				m.showSlide(m.slide + 1)
+				return nil
+			}
+			m.phase = phaseTask
+			m.layout()
+			return m.watch()
+		case "left", "h":
+			if m.slide > 0 {
+				m.showSlide(m.slide - 1)
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		m.slideView, cmd = m.slideView.Update(msg)
+		return cmd
+
+	case phaseTask:
+		if msg.String() != "s" {
+			m.givingUp = false
+		}
+		switch msg.String() {
+		case "left", "h":
+			if n := len(m.currentLesson().Slides); n > 0 {
+				m.phase = phaseSlides
+				m.showSlide(n - 1)
+			}
+			return nil
+		case "enter":
+			if m.verdict != nil && m.verdict.IsCorrect {
+				return m.nextLesson()
+			}
+			if m.busy == "" {
+				return m.judge()
+			}
+			return nil
+		case "e":
+			if m.busy == "" {
+				return m.edit()
+			}
+			return nil
+		case "?":
+			if m.busy == "" {
+				return m.hint()
+			}
+			return nil
+		case "s":
+			return m.giveUp()
+		case "tab":
+			if m.results != nil && m.focus == paneTask {
+				m.focus = paneResults
+			} else {
+				m.focus = paneTask
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		if m.focus == paneResults {
+			m.resultsView, cmd = m.resultsView.Update(msg)
+		} else {
+			m.taskView, cmd = m.taskView.Update(msg)
+		}
+		return cmd
+
+	case phaseDone:
+		if msg.String() == "enter" {
+			return tea.Quit
+		}
+	}
+	return nil
+}
+
+func (m *model) handlePickerKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		m.pick = max(m.pick-1, 0)
+	case "down", "j":
+		m.pick = min(m.pick+1, len(m.opts.Course.Lessons)-1)
+	case "enter":
+		m.picking = false
+		return m.openLesson(m.pick)
+	case "esc", "g":
+		m.picking = false
+	}
+	return nil
+}
+
+func (m *model) currentLesson() course.Lesson {
+	return m.opts.Course.Lessons[m.lesson]
+}
+
+func (m *model) filePath() string {
+	return course.LessonFile(m.opts.CoursePath, m.currentLesson())
+}
+
+func (m *model) lessonCtx() context.Context {
+	return usage.WithLesson(m.visitCtx, m.opts.Course.ID, m.currentLesson().ID)
+}
+
+func (m *model) showSlide(i int) {
+	m.slide = i
+	m.refresh()
+	m.slideView.GotoTop()
+}
+
+// judge runs the lesson file and then judges the results.
+func (m *model) judge() tea.Cmd {
+	m.busy = "Running..."
+	m.status = ""
+	return tea.Batch(m.spinner.Tick, m.run(false))
+}
+
+func (m *model) run(check bool) tea.Cmd {
+	ctx, lesson, visit := m.lessonCtx(), m.currentLesson(), m.visit
+	language, filePath := m.opts.Course.ProgrammingLanguage, m.filePath()
+	return func() tea.Msg {
+		// 実行中に保存されても履歴と結果が食い違わないよう、先に読んでおく
+		code, err := os.ReadFile(filePath)
+		if err != nil {
+			return errMsg{err}
+		}
+		results, err := judge.Run(ctx, lesson, language, filePath)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return ranMsg{visit: visit, results: results, code: code, check: check, err: err}
+	}
+}
+
+// judged shows the results and asks the AI judge unless the test cases
+// settle it.
+func (m *model) judged(results []judge.Result, code []byte) tea.Cmd {
+	m.results = results
+	m.code = code
+	m.verdict = nil
+	m.focus = paneResults
+	m.layout()
+	m.refresh()
+	m.resultsView.GotoTop()
+
+	if v, ok := judge.Local(m.currentLesson(), results); ok {
+		return m.showVerdict(v)
+	}
+
+	m.busy = "Judging..."
+	ctx, c, lesson, visit, filePath := m.lessonCtx(), m.opts.Course, m.currentLesson(), m.visit, m.filePath()
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		v, err := judge.AskAI(ctx, c, lesson, filePath, results)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return verdictMsg{visit: visit, verdict: v, err: err}
+	})
+}
+
+func (m *model) showVerdict(v judge.Verdict) tea.Cmd {
+	m.verdict = &v
+	m.attempts = append(m.attempts, attemptSummary(len(m.attempts)+1, m.currentLesson(), v))
+	c, l := m.opts.Course, m.currentLesson()
+	attempt := history.Attempt{Output: tutor.RunOutput(l, m.results), IsCorrect: v.IsCorrect, Score: v.Score, Advice: v.Advice}
+	if err := history.Record(m.opts.CoursePath, l, m.code, attempt); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	if v.Score != nil {
+		if err := course.RecordScore(c.ID, l.ID, m.opts.ProgressPath, len(c.Lessons), *v.Score); err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+	}
+	m.refresh()
+	m.resultsView.GotoTop()
+	if !v.IsCorrect {
+		return m.failedAttempt()
+	}
+
+	m.completed[l.ID] = true
+	// 完了済みのレッスンを復習しても、続きから始める位置は戻さない
+	if err := course.SaveProgress(c.ID, l.ID, m.nextUp(), m.opts.ProgressPath, len(c.Lessons)); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.refresh()
+	return nil
+}
+
+// nextUp returns the first lesson that is not completed, or "" when the
+// course is.
+func (m *model) nextUp() string {
+	for _, l := range m.opts.Course.Lessons {
+		if !m.completed[l.ID] {
+			return l.ID
+		}
+	}
+	return ""
+}
+
+func (m *model) nextLesson() tea.Cmd {
+	if m.lesson+1 >= len(m.opts.Course.Lessons) {
+		m.leaveLesson()
+		m.phase = phaseDone
+		m.layout()
+		return nil
+	}
+	return m.openLesson(m.lesson + 1)
+}
+
+// openLesson shows the slides of lesson i, or its task if it has none.
+func (m *model) openLesson(i int) tea.Cmd {
+	m.leaveLesson()
+	m.visitCtx, m.cancelVisit = context.WithCancel(m.ctx)
+	m.lesson = i
+	m.phase = phaseSlides
+	m.layout()
+	m.showSlide(0)
+	m.taskView.GotoTop()
+	if err := m.showCachedSolution(); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	if len(m.currentLesson().Slides) == 0 {
+		m.phase = phaseTask
+		return m.watch()
+	}
+	return nil
+}
+
+// leaveLesson stops everything that belongs to the current lesson.
+func (m *model) leaveLesson() {
+	m.visit++
+	if m.cancelVisit != nil {
+		m.cancelVisit()
+	}
+	m.closeWatcher()
+	m.busy = ""
+	m.results = nil
+	m.code = nil
+	m.verdict = nil
+	m.attempts = nil
+	m.hints = nil
+	m.solution = nil
+	m.givingUp = false
+	m.chatting = false
+	m.chat = nil
+	m.asking = ""
+	m.status = ""
+	m.focus = paneTask
+}
+
+// edit opens the lesson file in the editor, which takes over the terminal
+// until it exits.
+func (m *model) edit() tea.Cmd {
+	command := editorCommand()
+	cmd := exec.Command(command[0], append(command[1:], m.filePath())...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editedMsg{err}
+	})
+}
+
+// watch starts watching the lesson file in watch mode.
+func (m *model) watch() tea.Cmd {
+	// html はブラウザで開くだけなので確認しない
+	if !m.opts.Watch || m.watcher != nil || m.opts.Course.ProgrammingLanguage == "html" {
+		return nil
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	// エディタは一時ファイルを rename して保存することが多いので、ディレクトリごと監視する
+	if err := w.Add(filepath.Dir(m.filePath())); err != nil {
+		w.Close()
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.watcher = w
+	m.status = "Watching the file for changes."
+	return waitForChange(w, m.filePath())
+}
+
+func (m *model) closeWatcher() {
+	if m.watcher != nil {
+		m.watcher.Close()
+		m.watcher = nil
+	}
+	m.changes++ // drops a pending check
+}
+
+func (m *model) debounce() tea.Cmd {
+	changes := m.changes
+	return tea.Tick(watchDebounce, func(time.Time) tea.Msg {
+		return debouncedMsg{changes}
+	})
+}
+
+// waitForChange waits for the next save of filePath. It returns nil once the
+// watcher is closed.
+func waitForChange(w *fsnotify.Watcher, filePath string) tea.Cmd {
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-w.Events:
+				if !ok {
+					return nil
+				}
+				if filepath.Clean(event.Name) == filePath && event.Has(fsnotify.Write|fsnotify.Create) {
+					return fileChangedMsg{w}
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return nil
+				}
+				return errMsg{err}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/tutor"
+)
+
+type solutionMsg struct {
+	visit    int
+	solution tutor.Solution
+	err      error
+}
+
+// giveUp reveals the reference solution when s is pressed twice in a row.
+func (m *model) giveUp() tea.Cmd {
+	switch {
+	case m.solution != nil:
+		m.focus = paneTask
+		m.taskView.GotoBottom()
+		return nil
+	case m.busy != "":
+		return nil
+	case !m.givingUp:
+		m.givingUp = true
+		m.status = "Press s again to give up and see the reference solution."
+		return nil
+	}
+	m.givingUp = false
+	return m.revealSolution()
+}
+
+// failedAttempt counts a wrong submission and reveals the solution once
+// there have been solution.reveal_after of them.
+func (m *model) failedAttempt() tea.Cmd {
+	c, l := m.opts.Course, m.currentLesson()
+	failed, err := course.RecordFailedAttempt(c.ID, l.ID, m.opts.ProgressPath, len(c.Lessons))
+	if err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.failed[l.ID] = failed
+
+	revealAfter := tutor.RevealAfter()
+	if m.solution != nil || revealAfter == 0 || failed < revealAfter {
+		return nil
+	}
+	cmd := m.revealSolution()
+	m.status = fmt.Sprintf("%d failed attempts: here is the reference solution.", failed)
+	return cmd
+}
+
+// revealSolution shows the reference solution, which is generated on first
+// use and cached in the course directory.
+func (m *model) revealSolution() tea.Cmd {
+	m.busy = "Preparing the solution..."
+	m.status = ""
+	ctx, c, l, coursePath, visit := m.lessonCtx(), m.opts.Course, m.currentLesson(), m.opts.CoursePath, m.visit
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		s, err := tutor.LoadSolution(ctx, c, l, coursePath)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return solutionMsg{visit: visit, solution: s, err: err}
+	})
+}
+
+// showSolution adds the solution under the task and records in progress.json
+// that it was revealed. A failed generation is only reported.
+func (m *model) showSolution(msg solutionMsg) tea.Cmd {
+	if msg.err != nil {
+		m.status = "Could not prepare the solution: " + msg.err.Error()
+		return nil
+	}
+
+	c, l := m.opts.Course, m.currentLesson()
+	if err := course.RecordSolutionRevealed(c.ID, l.ID, m.opts.ProgressPath, len(c.Lessons)); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	m.revealed[l.ID] = true
+
+	m.solution = &msg.solution
+	m.focus = paneTask
+	m.refresh()
+	m.taskView.GotoBottom()
+	return nil
+}
+
+// showCachedSolution shows the solution again when revisiting a lesson
+// whose solution was already revealed.
+func (m *model) showCachedSolution() error {
+	l := m.currentLesson()
+	if !m.revealed[l.ID] {
+		return nil
+	}
+	s, ok, err := tutor.CachedSolution(m.opts.CoursePath, l)
+	if err != nil || !ok {
+		return err
+	}
+	m.solution = &s
+	m.refresh()
+	return nil
+}
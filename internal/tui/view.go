@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	sidebarWidth    = 28 // without the border
+	minSidebarWidth = 72 // the sidebar is hidden in narrower terminals
+)
+
+var (
+	headerStyle        = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1)
+	paneTitleStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	blurredTitleStyle  = lipgloss.NewStyle().Faint(true)
+	sidebarStyle       = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderRight(true).BorderForeground(lipgloss.Color("240")).PaddingRight(1)
+	currentLessonStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	completedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	revealedStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("214")) // completed with the solution revealed
+	helpStyle          = lipgloss.NewStyle().Faint(true)
+)
+
+func (m *model) showSidebar() bool {
+	return m.width >= minSidebarWidth
+}
+
+func (m *model) bodyHeight() int {
+	return max(m.height-3, 2) // header, status and key help
+}
+
+func (m *model) mainWidth() int {
+	if m.showSidebar() {
+		return m.width - sidebarWidth - 2 // border and padding
+	}
+	return m.width
+}
+
+// layout sizes the panes for the window and the current phase.
+func (m *model) layout() {
+	if m.width == 0 {
+		return
+	}
+	width, height := m.mainWidth(), m.bodyHeight()-1 // pane title
+
+	m.slideView.Width, m.slideView.Height = width, height
+	m.chatView.Width, m.chatView.Height = width, max(height-2, 1) // blank line and input
+	m.chatInput.Width = max(width-4, 10)
+	m.taskView.Width, m.resultsView.Width = width, width
+	if m.results == nil {
+		m.taskView.Height = height
+		return
+	}
+	// 結果を見ながら課題も読めるように、課題を上に残す
+	height-- // title of the results pane
+	m.taskView.Height = max(height*2/5, 1)
+	m.resultsView.Height = max(height-m.taskView.Height, 1)
+}
+
+// refresh renders the markdown of the current lesson into the panes.
+func (m *model) refresh() {
+	if m.width == 0 {
+		return
+	}
+	l := m.currentLesson()
+	if m.slide < len(l.Slides) {
+		m.slideView.SetContent(m.render(l.Slides[m.slide]))
+	}
+	task := taskMarkdown(l, m.filePath())
+	if len(m.hints) > 0 {
+		task += "\n\n## Hints\n" + strings.Join(m.hints, "\n\n")
+	}
+	if m.solution != nil {
+		task += solutionMarkdown(m.opts.Course.ProgrammingLanguage, *m.solution)
+	}
+	if m.completed[l.ID] {
+		task += codeMarkdown(m.opts.Course.ProgrammingLanguage, m.filePath())
+	}
+	m.taskView.SetContent(m.render(task))
+	if m.results != nil {
+		m.resultsView.SetContent(m.render(resultsMarkdown(l, m.results, m.verdict)))
+	}
+	if m.chat != nil {
+		m.chatView.SetContent(m.render(chatMarkdown(m.chat.Messages, m.asking)))
+	}
+}
+
+// render renders markdown wrapped to the main area. Glamour's auto style
+// would query the terminal, so the style is chosen once in Run.
+func (m *model) render(md string) string {
+	wrap := max(m.mainWidth()-4, 20)
+	if m.renderer == nil || m.wrap != wrap {
+		r, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(m.style),
+			glamour.WithWordWrap(wrap),
+		)
+		if err != nil {
+			return md
+		}
+		m.renderer, m.wrap = r, wrap
+	}
+	out, err := m.renderer.Render(md)
+	if err != nil {
+		return md
+	}
+	return out
+}
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	body := lipgloss.NewStyle().Height(m.bodyHeight()).MaxHeight(m.bodyHeight()).Render(m.mainView())
+	if m.showSidebar() {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, m.sidebarView(), lipgloss.NewStyle().PaddingLeft(1).Render(body))
+	}
+
+	line := lipgloss.NewStyle().MaxWidth(m.width)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Width(m.width).MaxWidth(m.width).Render(m.header()),
+		body,
+		line.Render(m.statusLine()),
+		line.Render(helpStyle.Render(m.help())),
+	)
+}
+
+func (m *model) header() string {
+	c := m.opts.Course
+	if m.phase == phaseDone {
+		return fmt.Sprintf("🐐 %s - Finished!", c.Title)
+	}
+	header := fmt.Sprintf("🐐 %s - %s (%d/%d)", c.Title, m.currentLesson().Title, m.lesson+1, len(c.Lessons))
+	if m.opts.Watch {
+		header += "  [watch]"
+	}
+	return header
+}
+
+func (m *model) mainView() string {
+	if m.chatting {
+		return paneTitle("Ask the tutor", true, m.chatView) + "\n" + m.chatView.View() + "\n\n" + m.chatInput.View()
+	}
+	if m.picking {
+		return m.pickerView()
+	}
+
+	switch m.phase {
+	case phaseSlides:
+		title := fmt.Sprintf("Slide %d/%d", m.slide+1, len(m.currentLesson().Slides))
+		return paneTitle(title, true, m.slideView) + "\n" + m.slideView.View()
+
+	case phaseTask:
+		view := paneTitle("Task", m.focus == paneTask, m.taskView) + "\n" + m.taskView.View()
+		if m.results != nil {
+			view += "\n" + paneTitle("Result", m.focus == paneResults, m.resultsView) + "\n" + m.resultsView.View()
+		}
+		return view
+	}
+
+	message := fmt.Sprintf("## 🎉 Course Completed! 🐐 \n\nYou've completed the course: %s", m.opts.Course.Title)
+	return m.render(message)
+}
+
+// paneTitle shows which pane scrolls and whether there is more below.
+func paneTitle(title string, focused bool, v viewport.Model) string {
+	if !v.AtBottom() {
+		title += " ↓"
+	}
+	if focused {
+		return paneTitleStyle.Render("▌" + title)
+	}
+	return blurredTitleStyle.Render(" " + title)
+}
+
+// pickerView lists the lessons to open. Completed lessons can be revisited
+// to read their slides and code again.
+func (m *model) pickerView() string {
+	lines := []string{paneTitleStyle.Render("▌Go to lesson"), ""}
+	for i, l := range m.opts.Course.Lessons {
+		cursor, style := "  ", lipgloss.NewStyle()
+		if i == m.pick {
+			cursor, style = "› ", currentLessonStyle
+		}
+		mark := "·"
+		if m.completed[l.ID] {
+			mark = "✓"
+		}
+		lines = append(lines, style.MaxWidth(m.mainWidth()).Render(fmt.Sprintf("%s%s %d. %s", cursor, mark, i+1, l.Title)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) sidebarView() string {
+	lessons := m.opts.Course.Lessons
+	width := sidebarWidth - 1 // padding
+
+	done := 0
+	lines := []string{}
+	for i, l := range lessons {
+		mark, style := "·", lipgloss.NewStyle()
+		switch {
+		case m.completed[l.ID] && m.revealed[l.ID]:
+			mark, style = "✓", revealedStyle
+			done++
+		case m.completed[l.ID]:
+			mark, style = "✓", completedStyle
+			done++
+		case i == m.lesson && m.phase != phaseDone:
+			mark = "▶"
+		}
+		if i == m.lesson && m.phase != phaseDone {
+			style = currentLessonStyle
+		}
+		lines = append(lines, style.MaxWidth(width).Render(fmt.Sprintf("%s %d. %s", mark, i+1, l.Title)))
+	}
+
+	title := paneTitleStyle.Render("Lessons") + helpStyle.Render(fmt.Sprintf(" %d/%d", done, len(lessons)))
+	content := title + "\n\n" + strings.Join(lines, "\n")
+	return sidebarStyle.Width(sidebarWidth).Height(m.bodyHeight()).MaxHeight(m.bodyHeight()).Render(content)
+}
+
+func (m *model) statusLine() string {
+	if m.busy != "" {
+		return m.spinner.View() + " " + m.busy
+	}
+	return m.status
+}
+
+func (m *model) help() string {
+	if m.chatting {
+		return "enter send • ↑/↓ scroll • esc close • ctrl+c quit"
+	}
+	if m.picking {
+		return "↑/↓ select • enter open • esc cancel • q quit"
+	}
+
+	keys := []string{}
+	switch m.phase {
+	case phaseSlides:
+		keys = append(keys, "←/→ page", "↑/↓ scroll", "a ask")
+	case phaseTask:
+		switch {
+		case m.verdict != nil && m.verdict.IsCorrect:
+			keys = append(keys, "enter next lesson")
+		case m.opts.Watch:
+			keys = append(keys, "enter ask the AI judge")
+		default:
+			keys = append(keys, "enter judge")
+		}
+		keys = append(keys, "e editor", "? hint", "a ask")
+		if m.solution == nil {
+			keys = append(keys, "s give up")
+		}
+		keys = append(keys, "← slides")
+		if m.results != nil {
+			keys = append(keys, "tab switch pane")
+		}
+		keys = append(keys, "↑/↓ scroll")
+	case phaseDone:
+		keys = append(keys, "enter quit")
+	}
+	return strings.Join(append(keys, "g lessons", "q quit"), " • ")
+}
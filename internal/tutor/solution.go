@@ -0,0 +1,84 @@
+package tutor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/llm"
+	"github.com/spf13/viper"
+)
+
+// solutionsDir keeps the reference solutions in the course directory, out of
+// the lesson directories the student works in.
+const solutionsDir = ".solutions"
+
+// DefaultRevealAfter is the number of failed attempts after which the
+// solution is revealed, configurable under solution.reveal_after.
+const DefaultRevealAfter = 3
+
+// Solution is the reference solution of a lesson.
+type Solution struct {
+	Code        string `json:"code"`
+	Explanation string `json:"explanation"`
+}
+
+// RevealAfter returns solution.reveal_after from config.yaml. Zero means the
+// solution is only shown when the student gives up.
+func RevealAfter() int {
+	if viper.IsSet("solution.reveal_after") {
+		return max(viper.GetInt("solution.reveal_after"), 0)
+	}
+	return DefaultRevealAfter
+}
+
+func solutionPath(coursePath string, lesson course.Lesson) string {
+	return filepath.Join(coursePath, solutionsDir, filepath.Base(lesson.ID)+".json")
+}
+
+// CachedSolution returns the solution of the lesson if it was generated
+// before.
+func CachedSolution(coursePath string, lesson course.Lesson) (Solution, bool, error) {
+	var s Solution
+	data, err := os.ReadFile(solutionPath(coursePath, lesson))
+	if os.IsNotExist(err) {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, false, fmt.Errorf("failed to parse the solution of %s: %w", lesson.ID, err)
+	}
+	return s, true, nil
+}
+
+// LoadSolution returns the cached solution of the lesson, generating and
+// caching it on first use.
+func LoadSolution(ctx context.Context, c course.Course, lesson course.Lesson, coursePath string) (Solution, error) {
+	s, ok, err := CachedSolution(coursePath, lesson)
+	if err != nil || ok {
+		return s, err
+	}
+
+	response, err := llm.GenerateSolution(ctx, lesson, c.ProgrammingLanguage, c.Title)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal([]byte(response), &s); err != nil {
+		return s, err
+	}
+
+	path := solutionPath(coursePath, lesson)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return s, err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return s, err
+	}
+	return s, os.WriteFile(path, data, 0644)
+}
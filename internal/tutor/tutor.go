@@ -0,0 +1,125 @@
+package tutor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minotto165/progoat/internal/course"
+	"github.com/minotto165/progoat/internal/judge"
+	"github.com/minotto165/progoat/internal/llm"
+)
+
+// Files kept in the lesson directory.
+const (
+	ChatFile    = "chat.json"     // the tutor conversation
+	lastRunFile = ".last_run.txt" // output of the latest run, for questions asked outside a session
+)
+
+// Conversation is the tutor chat of one lesson, saved in its directory so it
+// can be resumed.
+type Conversation struct {
+	path     string
+	Messages []llm.ChatMessage
+}
+
+// Open loads the conversation of the lesson in lessonDir. It is empty if
+// none was saved yet.
+func Open(lessonDir string) (*Conversation, error) {
+	c := &Conversation{path: filepath.Join(lessonDir, ChatFile)}
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.Messages); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", c.path, err)
+	}
+	return c, nil
+}
+
+// Add appends a question and its answer and saves the conversation.
+func (c *Conversation) Add(question, answer string) error {
+	now := time.Now()
+	c.Messages = append(c.Messages,
+		llm.ChatMessage{Role: llm.ChatUser, Content: question, Time: now},
+		llm.ChatMessage{Role: llm.ChatAssistant, Content: answer, Time: now},
+	)
+	data, err := json.MarshalIndent(c.Messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Clear deletes the conversation to start over.
+func (c *Conversation) Clear() error {
+	c.Messages = nil
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Answer asks the tutor question, following the earlier turns in history.
+// Nothing is saved; call Add with the answer.
+func Answer(ctx context.Context, lesson llm.LessonContext, history []llm.ChatMessage, question string) (string, error) {
+	history = append(history[:len(history):len(history)], llm.ChatMessage{Role: llm.ChatUser, Content: question})
+	response, err := llm.GenerateAnswer(ctx, lesson, history)
+	if err != nil {
+		return "", err
+	}
+	var answer struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.Unmarshal([]byte(response), &answer); err != nil {
+		return "", err
+	}
+	return answer.Answer, nil
+}
+
+// LessonContext gathers the lesson, the student's current code and the
+// output of the last run for the tutor.
+func LessonContext(c course.Course, lesson course.Lesson, coursePath string) llm.LessonContext {
+	lessonDir := course.LessonDir(coursePath, lesson)
+	code, _ := os.ReadFile(course.LessonFile(coursePath, lesson))
+	lastRun, _ := os.ReadFile(filepath.Join(lessonDir, lastRunFile))
+	return llm.LessonContext{
+		CourseTitle: c.Title,
+		LessonTitle: lesson.Title,
+		Slides:      lesson.Slides,
+		Task:        lesson.TaskDescription,
+		Code:        string(code),
+		LastRun:     string(lastRun),
+	}
+}
+
+// SaveLastRun keeps the output of a run in the lesson directory.
+func SaveLastRun(lessonDir string, lesson course.Lesson, results []judge.Result) error {
+	return os.WriteFile(filepath.Join(lessonDir, lastRunFile), []byte(RunOutput(lesson, results)), 0644)
+}
+
+// RunOutput describes a run for the tutor. Hidden test cases stay hidden.
+func RunOutput(lesson course.Lesson, results []judge.Result) string {
+	first := results[0]
+	_, status := judge.Status(lesson, results)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Status: %s\n", status)
+	if first.Output != "" {
+		fmt.Fprintf(&b, "Stdout:\n%s\n", first.Output)
+	}
+	if first.Stderr != "" {
+		fmt.Fprintf(&b, "Stderr:\n%s\n", first.Stderr)
+	}
+	if len(lesson.Cases()) > 0 {
+		b.WriteString(judge.Summary(results))
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is the token usage of one LLM call.
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Command          string    `json:"command"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	CourseID         string    `json:"course_id,omitempty"`
+	LessonID         string    `json:"lesson_id,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Model  string  `mapstructure:"model"`
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+}
+
+func (p Price) Cost(e Entry) float64 {
+	return float64(e.PromptTokens)/1e6*p.Input + float64(e.CompletionTokens)/1e6*p.Output
+}
+
+// Recorder collects the usage of one command and appends it to the ledger
+// when saved. Entries without a course get the recorder's CourseID, which is
+// useful when the course is only known after the first call.
+type Recorder struct {
+	mu       sync.Mutex
+	path     string
+	command  string
+	CourseID string
+	entries  []Entry
+}
+
+func NewRecorder(path, command string) *Recorder {
+	return &Recorder{path: path, command: command}
+}
+
+// Add records an entry. It does nothing on a nil recorder.
+func (r *Recorder) Add(e Entry) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Command = r.command
+	r.entries = append(r.entries, e)
+}
+
+// Save appends the recorded entries to the ledger.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, e := range r.entries {
+		if e.CourseID == "" {
+			e.CourseID = r.CourseID
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	r.entries = nil
+	return nil
+}
+
+// Load reads every entry of the ledger.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // 壊れた行は無視
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+type recorderKey struct{}
+type lessonKey struct{}
+
+type lessonLabel struct {
+	courseID string
+	lessonID string
+}
+
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder of ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// WithLesson labels the LLM calls made with ctx.
+func WithLesson(ctx context.Context, courseID, lessonID string) context.Context {
+	return context.WithValue(ctx, lessonKey{}, lessonLabel{courseID, lessonID})
+}
+
+// LessonFromContext returns the labels set by WithLesson.
+func LessonFromContext(ctx context.Context) (courseID, lessonID string) {
+	l, _ := ctx.Value(lessonKey{}).(lessonLabel)
+	return l.courseID, l.lessonID
+}
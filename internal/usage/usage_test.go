@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+
+	r := NewRecorder(path, "generate")
+	r.Add(Entry{Model: "m", PromptTokens: 10})
+	r.Add(Entry{Model: "m", CourseID: "other", PromptTokens: 5})
+	// コースIDは最初の呼び出しの後で決まることがある
+	r.CourseID = "go-basics"
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// 保存済みの記録は二重に書かない
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	for i, want := range []string{"go-basics", "other"} {
+		if entries[i].CourseID != want || entries[i].Command != "generate" || entries[i].Time.IsZero() {
+			t.Errorf("entries[%d] = %+v, want course %s from generate", i, entries[i], want)
+		}
+	}
+}
+
+func TestRecorderSaveFails(t *testing.T) {
+	r := NewRecorder(filepath.Join(t.TempDir(), "missing", "usage.jsonl"), "start")
+	r.Add(Entry{Model: "m"})
+	if err := r.Save(); err == nil {
+		t.Error("Save into a missing directory succeeded")
+	}
+}
+
+func TestLoadSkipsBrokenLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	ledger := `{"command":"start","model":"a","prompt_tokens":1}
+{"command":"start","model":
+not json
+{"command":"ask","model":"b","prompt_tokens":2}
+`
+	if err := os.WriteFile(path, []byte(ledger), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Model != "a" || entries[1].Model != "b" {
+		t.Errorf("entries = %+v, want a and b", entries)
+	}
+}
+
+func TestLoadMissingLedger(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "usage.jsonl"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Load() = %v, %v, want no entries", entries, err)
+	}
+}